@@ -0,0 +1,485 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/iakrevetkho/components-tests/cott/domain"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	PODMAN_ROOTFUL_SOCKET       = "unix:///run/podman/podman.sock"
+	PODMAN_ROOTLESS_SOCKET_PATH = "podman/podman.sock"
+	XDG_RUNTIME_DIR_ENV_VAR     = "XDG_RUNTIME_DIR"
+)
+
+type dockerContainerLauncherUsecase struct {
+	cli         *client.Client
+	registryCfg domain.RegistryConfig
+}
+
+// NewDockerContainerLauncherUsecase creates launcher for Docker engine. Settings from env vars are used if host is empty
+func NewDockerContainerLauncherUsecase(host string, registryCfg domain.RegistryConfig) (ContainerLauncherUsecase, error) {
+	// Credentials without the server would be sent to every registry
+	if registryCfg.Username != "" && registryCfg.Server == "" {
+		return nil, domain.REGISTRY_SERVER_IS_REQUIRED
+	}
+
+	cluc := new(dockerContainerLauncherUsecase)
+	cluc.registryCfg = registryCfg
+
+	opts := []client.Opt{client.FromEnv}
+	if host != "" {
+		opts = append(opts, client.WithHost(host))
+	}
+
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+	cluc.cli = cli
+
+	return cluc, nil
+}
+
+// NewPodmanContainerLauncherUsecase creates launcher for Podman engine via its Docker-compatible API socket.
+// Rootless socket is used if host is empty and it exists, otherwise rootful socket.
+func NewPodmanContainerLauncherUsecase(host string, registryCfg domain.RegistryConfig) (ContainerLauncherUsecase, error) {
+	if host == "" {
+		host = PODMAN_ROOTFUL_SOCKET
+		if runtimeDir := os.Getenv(XDG_RUNTIME_DIR_ENV_VAR); runtimeDir != "" {
+			rootlessSocket := filepath.Join(runtimeDir, PODMAN_ROOTLESS_SOCKET_PATH)
+			if _, err := os.Stat(rootlessSocket); err == nil {
+				host = "unix://" + rootlessSocket
+			}
+		}
+	}
+	logrus.WithField("host", host).Debug("use podman socket")
+
+	return NewDockerContainerLauncherUsecase(host, registryCfg)
+}
+
+func (cluc *dockerContainerLauncherUsecase) PullImage(image string, pullPolicy domain.PullPolicy) (*domain.ImageInfo, error) {
+	imageInfo := &domain.ImageInfo{Image: image}
+
+	switch pullPolicy {
+
+	case domain.PullPolicy_Always:
+		imageInfo.Pulled = true
+
+	case domain.PullPolicy_IfNotPresent, domain.PullPolicy_Never:
+		_, _, err := cluc.cli.ImageInspectWithRaw(context.Background(), image)
+		if client.IsErrNotFound(err) {
+			if pullPolicy == domain.PullPolicy_Never {
+				return nil, domain.IMAGE_NOT_PRESENT_LOCALLY
+			}
+			imageInfo.Pulled = true
+		} else if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, domain.UNKNOWN_PULL_POLICY
+	}
+
+	if imageInfo.Pulled {
+		registryAuth, err := cluc.createRegistryAuth(image)
+		if err != nil {
+			return nil, err
+		}
+
+		startTime := time.Now()
+		reader, err := cluc.cli.ImagePull(context.Background(), image, types.ImagePullOptions{RegistryAuth: registryAuth})
+		if err != nil {
+			return nil, err
+		}
+		buf := new(strings.Builder)
+		if _, err := io.Copy(buf, reader); err != nil {
+			reader.Close()
+			return nil, err
+		}
+		logrus.Trace(buf)
+		reader.Close()
+		imageInfo.PullDurationInMicros = time.Since(startTime).Microseconds()
+		logrus.WithFields(logrus.Fields{"image": image, "duration": time.Since(startTime)}).Debug("container image pulled")
+	}
+
+	inspect, _, err := cluc.cli.ImageInspectWithRaw(context.Background(), image)
+	if err != nil {
+		return nil, err
+	}
+	imageInfo.SizeInBytes = inspect.Size
+	imageInfo.LayersCount = len(inspect.RootFS.Layers)
+	if len(inspect.RepoDigests) > 0 {
+		imageInfo.Digest = inspect.RepoDigests[0]
+	}
+
+	return imageInfo, nil
+}
+
+func (cluc *dockerContainerLauncherUsecase) LaunchContainer(cfg *domain.ContainerConfig) (*string, error) {
+	logrus.WithFields(logrus.Fields{"image": cfg.Image, "envVarMap": cfg.EnvVars, "port": cfg.Port}).Debug("launch container")
+
+	containerPort := nat.Port(strconv.FormatUint(uint64(cfg.Port), 10))
+	containerCfg := &container.Config{
+		Image: cfg.Image,
+		Env:   convertEnvVarsMapToSlice(cfg.EnvVars),
+		Cmd:   cfg.Cmd,
+		ExposedPorts: nat.PortSet{
+			containerPort: struct{}{},
+		},
+	}
+	hostCfg := &container.HostConfig{
+		Resources: container.Resources{
+			NanoCPUs: int64(cfg.Resources.Cpus * 1e9),
+			Memory:   cfg.Resources.MemoryInBytes,
+		},
+	}
+	if cfg.NetworkMode == domain.NetworkMode_Host {
+		hostCfg.NetworkMode = container.NetworkMode(domain.NetworkMode_Host)
+	} else if cfg.HostPort != 0 {
+		hostCfg.PortBindings = nat.PortMap{
+			containerPort: []nat.PortBinding{
+				nat.PortBinding{
+					HostIP:   "0.0.0.0",
+					HostPort: strconv.FormatUint(uint64(cfg.HostPort), 10),
+				},
+			},
+		}
+	}
+	for _, m := range cfg.Mounts {
+		dockerMount, err := cluc.convertMount(m)
+		if err != nil {
+			return nil, err
+		}
+		hostCfg.Mounts = append(hostCfg.Mounts, *dockerMount)
+	}
+	var networkingCfg *network.NetworkingConfig
+	if cfg.Network != "" && cfg.NetworkMode != domain.NetworkMode_Host {
+		networkingCfg = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				cfg.Network: {Aliases: []string{cfg.Alias}},
+			},
+		}
+	}
+
+	resp, err := cluc.cli.ContainerCreate(context.Background(), containerCfg, hostCfg, networkingCfg, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithFields(logrus.Fields{"image": cfg.Image, "id": resp.ID}).Debug("container created")
+
+	if err := cluc.cli.ContainerStart(context.Background(), resp.ID, types.ContainerStartOptions{}); err != nil {
+		return nil, err
+	}
+	logrus.WithFields(logrus.Fields{"image": cfg.Image, "id": resp.ID}).Debug("container started")
+
+	return &resp.ID, nil
+}
+
+func (cluc *dockerContainerLauncherUsecase) GetStorageDriver() (string, error) {
+	info, err := cluc.cli.Info(context.Background())
+	if err != nil {
+		return "", err
+	}
+	return info.Driver, nil
+}
+
+func (cluc *dockerContainerLauncherUsecase) GetEngineInfo() (*domain.EngineInfo, error) {
+	version, err := cluc.cli.ServerVersion(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	info, err := cluc.cli.Info(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	// Platform name distinguishes Docker and Podman engines
+	return &domain.EngineInfo{
+		Name:          version.Platform.Name,
+		Version:       version.Version,
+		StorageDriver: info.Driver,
+		CgroupVersion: info.CgroupVersion,
+	}, nil
+}
+
+func (cluc *dockerContainerLauncherUsecase) CreateNetwork(name string) (*string, error) {
+	resp, err := cluc.cli.NetworkCreate(context.Background(), name, types.NetworkCreate{CheckDuplicate: true, Driver: "bridge"})
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithFields(logrus.Fields{"name": name, "id": resp.ID}).Debug("network created")
+
+	return &resp.ID, nil
+}
+
+func (cluc *dockerContainerLauncherUsecase) RemoveNetwork(id string) error {
+	if err := cluc.cli.NetworkRemove(context.Background(), id); err != nil {
+		return err
+	}
+	logrus.WithField("id", id).Debug("network removed")
+
+	return nil
+}
+
+func (cluc *dockerContainerLauncherUsecase) StopContainer(id string) error {
+	if err := cluc.cli.ContainerStop(context.Background(), id, &STOP_CONTAINER_TIMEOUT); err != nil {
+		return err
+	}
+	logrus.WithField("id", id).Debug("container stopped")
+
+	return nil
+}
+
+func (cluc *dockerContainerLauncherUsecase) RemoveContainer(id string) error {
+	if err := cluc.cli.ContainerRemove(context.Background(), id, types.ContainerRemoveOptions{RemoveVolumes: true}); err != nil {
+		return err
+	}
+	logrus.WithField("id", id).Debug("container removed")
+
+	return nil
+}
+
+func (cluc *dockerContainerLauncherUsecase) RemoveVolume(name string) error {
+	if err := cluc.cli.VolumeRemove(context.Background(), name, false); err != nil && !client.IsErrNotFound(err) {
+		return err
+	}
+	logrus.WithField("name", name).Debug("volume removed")
+
+	return nil
+}
+
+func (cluc *dockerContainerLauncherUsecase) GetContainerStats(id string) (*domain.ContainerStats, error) {
+	statsResponse, err := cluc.cli.ContainerStats(context.Background(), id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	statsBytes, err := io.ReadAll(statsResponse.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats types.StatsJSON
+
+	if err := json.Unmarshal(statsBytes, &stats); err != nil {
+		return nil, err
+	}
+
+	return cluc.addHostNetworks(id, cluc.convertStats(&stats.Stats, stats.Networks)), nil
+}
+
+func (cluc *dockerContainerLauncherUsecase) GetContainerStatsStream(id string) (<-chan *domain.ContainerStats, context.CancelFunc, error) {
+	ctx, ctxCancelFunc := context.WithCancel(context.Background())
+
+	statsResponse, err := cluc.cli.ContainerStats(ctx, id, true)
+	if err != nil {
+		ctxCancelFunc()
+		return nil, nil, err
+	}
+	logrus.WithField("id", id).Debug("start getting container stats")
+
+	statsCh := make(chan *domain.ContainerStats, 1)
+
+	// Goroutine for sending data from stats to channel
+	go func() {
+		defer close(statsCh)
+
+		decoder := json.NewDecoder(statsResponse.Body)
+		for {
+			select {
+			case <-ctx.Done():
+				statsResponse.Body.Close()
+				logrus.WithField("id", id).Debug("stop logging container stats. context done")
+				return
+			default:
+				var stats types.StatsJSON
+				if err := decoder.Decode(&stats); err == io.EOF {
+					logrus.WithField("id", id).Debug("stop logging container stats")
+					return
+				} else if err != nil {
+					ctxCancelFunc()
+					break
+				}
+				statsCh <- cluc.addHostNetworks(id, cluc.convertStats(&stats.Stats, stats.Networks))
+			}
+		}
+	}()
+
+	return statsCh, ctxCancelFunc, nil
+}
+
+// convertStats converts Docker stats into engine independent stats
+func (cluc *dockerContainerLauncherUsecase) convertStats(stats *types.Stats, networks map[string]types.NetworkStats) *domain.ContainerStats {
+	r := &domain.ContainerStats{
+		Read:        stats.Read,
+		CpuStats:    cluc.convertCpuStats(&stats.CPUStats),
+		MemoryStats: cluc.convertMemoryStats(&stats.MemoryStats),
+		Networks:    make(map[string]domain.ContainerNetworkStats),
+	}
+
+	r.BlkioStats.IoServiceBytesRecursive = cluc.convertBlkioEntries(stats.BlkioStats.IoServiceBytesRecursive)
+	r.BlkioStats.IoServicedRecursive = cluc.convertBlkioEntries(stats.BlkioStats.IoServicedRecursive)
+
+	for name, network := range networks {
+		r.Networks[name] = domain.ContainerNetworkStats{
+			RxBytes:   network.RxBytes,
+			TxBytes:   network.TxBytes,
+			RxPackets: network.RxPackets,
+			TxPackets: network.TxPackets,
+			RxDropped: network.RxDropped,
+			TxDropped: network.TxDropped,
+			RxErrors:  network.RxErrors,
+			TxErrors:  network.TxErrors,
+		}
+	}
+
+	return r
+}
+
+func (cluc *dockerContainerLauncherUsecase) convertCpuStats(stats *types.CPUStats) domain.ContainerCpuStats {
+	onlineCpus := stats.OnlineCPUs
+	// Old engines report only per CPU usage
+	if onlineCpus == 0 {
+		onlineCpus = uint32(len(stats.CPUUsage.PercpuUsage))
+	}
+
+	return domain.ContainerCpuStats{
+		TotalUsage:       stats.CPUUsage.TotalUsage,
+		UserUsage:        stats.CPUUsage.UsageInUsermode,
+		SystemUsage:      stats.CPUUsage.UsageInKernelmode,
+		OnlineCpus:       onlineCpus,
+		ThrottledPeriods: stats.ThrottlingData.ThrottledPeriods,
+		ThrottledTime:    stats.ThrottlingData.ThrottledTime,
+	}
+}
+
+// convertMemoryStats converts cgroup v1 or v2 memory stats. v1 keys are prefixed with total_ for the hierarchy
+func (cluc *dockerContainerLauncherUsecase) convertMemoryStats(stats *types.MemoryStats) domain.ContainerMemoryStats {
+	return domain.ContainerMemoryStats{
+		Usage:           stats.Usage,
+		MaxUsage:        stats.MaxUsage,
+		Limit:           stats.Limit,
+		Rss:             firstMemoryStat(stats.Stats, "total_rss", "rss", "anon"),
+		Cache:           firstMemoryStat(stats.Stats, "total_cache", "cache", "file"),
+		InactiveFile:    firstMemoryStat(stats.Stats, "total_inactive_file", "inactive_file"),
+		PageFaults:      firstMemoryStat(stats.Stats, "total_pgfault", "pgfault"),
+		MajorPageFaults: firstMemoryStat(stats.Stats, "total_pgmajfault", "pgmajfault"),
+	}
+}
+
+// firstMemoryStat returns value of the first present key
+func firstMemoryStat(stats map[string]uint64, keys ...string) uint64 {
+	for _, key := range keys {
+		if value, ok := stats[key]; ok {
+			return value
+		}
+	}
+	return 0
+}
+
+// GetOomKillsCount returns count of the OOM events since the container creation, because Docker stats don't contain them
+func (cluc *dockerContainerLauncherUsecase) GetOomKillsCount(id string) (uint64, error) {
+	inspect, err := cluc.cli.ContainerInspect(context.Background(), id)
+	if err != nil {
+		return 0, err
+	}
+	created, err := time.Parse(time.RFC3339Nano, inspect.Created)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	messages, errs := cluc.cli.Events(ctx, types.EventsOptions{
+		Since:   formatEventsTimestamp(created),
+		Until:   formatEventsTimestamp(time.Now()),
+		Filters: filters.NewArgs(filters.Arg("type", "container"), filters.Arg("container", id), filters.Arg("event", "oom")),
+	})
+
+	var count uint64
+	for {
+		select {
+		case <-messages:
+			count++
+		case err := <-errs:
+			// Stream is closed with EOF after the until time
+			if err != nil && err != io.EOF {
+				return count, err
+			}
+			return count, nil
+		}
+	}
+}
+
+// formatEventsTimestamp formats time as "seconds.nanoseconds" accepted by the events API
+func formatEventsTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
+
+// convertBlkioEntries converts cgroup v1 or v2 entries. Ops are "Read"/"Write" on v1 and "read"/"write" on v2
+func (cluc *dockerContainerLauncherUsecase) convertBlkioEntries(entries []types.BlkioStatEntry) []domain.ContainerBlkioStatEntry {
+	var r []domain.ContainerBlkioStatEntry
+	for _, entry := range entries {
+		r = append(r, domain.ContainerBlkioStatEntry{
+			Major: entry.Major,
+			Minor: entry.Minor,
+			Op:    strings.ToLower(entry.Op),
+			Value: entry.Value,
+		})
+	}
+	return r
+}
+
+// addHostNetworks reads network stats of the host networking container, because Docker doesn't report them.
+// Stats are read from the container process network namespace, so it works only with the local Docker engine.
+func (cluc *dockerContainerLauncherUsecase) addHostNetworks(id string, stats *domain.ContainerStats) *domain.ContainerStats {
+	if len(stats.Networks) > 0 {
+		return stats
+	}
+
+	inspect, err := cluc.cli.ContainerInspect(context.Background(), id)
+	if err != nil || inspect.State == nil || inspect.State.Pid == 0 || inspect.HostConfig == nil || !inspect.HostConfig.NetworkMode.IsHost() {
+		return stats
+	}
+
+	networks, err := readNetDev(filepath.Join("/proc", strconv.Itoa(inspect.State.Pid), "net", "dev"))
+	if err != nil {
+		logrus.WithError(err).WithField("id", id).Debug("couldn't read host network stats")
+		return stats
+	}
+	stats.Networks = networks
+	return stats
+}
+
+func (cluc *dockerContainerLauncherUsecase) convertMount(m domain.Mount) (*mount.Mount, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := &mount.Mount{Type: mount.Type(m.Type), Source: m.Source, Target: m.Target, ReadOnly: m.ReadOnly}
+	switch m.Type {
+	case domain.MountType_Volume:
+		r.VolumeOptions = &mount.VolumeOptions{DriverConfig: &mount.Driver{Name: m.GetDriver(), Options: m.DriverOptions}}
+	case domain.MountType_Tmpfs:
+		r.TmpfsOptions = &mount.TmpfsOptions{SizeBytes: m.TmpfsSizeInBytes}
+	}
+	return r, nil
+}
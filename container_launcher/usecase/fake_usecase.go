@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+// FakeContainerLauncherUsecase is an in-memory launcher for unit tests.
+// Errors and stats are scriptable through exported fields. All calls are recorded.
+type FakeContainerLauncherUsecase struct {
+	mu sync.Mutex
+
+	ImageInfo             *domain.ImageInfo
+	StorageDriver         string
+	PullImageErr          error
+	LaunchContainerErr    error
+	StopContainerErr      error
+	RemoveContainerErr    error
+	CreateNetworkErr      error
+	GetContainerStatsErr  error
+	GetContainerStatsFunc func(id string, call int) (*domain.ContainerStats, error)
+	// OomKillsCountFunc returns OOM kills count of the container on the call number
+	OomKillsCountFunc func(id string, call int) uint64
+
+	PulledImages          []string
+	LaunchedContainers    []*domain.ContainerConfig
+	StoppedContainers     []string
+	RemovedContainers     []string
+	RemovedVolumes        []string
+	CreatedNetworks       []string
+	RemovedNetworks       []string
+	GetStatsCallsCount    int
+	GetOomKillsCallsCount int
+}
+
+func NewFakeContainerLauncherUsecase() *FakeContainerLauncherUsecase {
+	return new(FakeContainerLauncherUsecase)
+}
+
+func (f *FakeContainerLauncherUsecase) PullImage(image string, pullPolicy domain.PullPolicy) (*domain.ImageInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.PullImageErr != nil {
+		return nil, f.PullImageErr
+	}
+	f.PulledImages = append(f.PulledImages, image)
+
+	if f.ImageInfo != nil {
+		return f.ImageInfo, nil
+	}
+	return &domain.ImageInfo{Image: image}, nil
+}
+
+func (f *FakeContainerLauncherUsecase) LaunchContainer(cfg *domain.ContainerConfig) (*string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.LaunchContainerErr != nil {
+		return nil, f.LaunchContainerErr
+	}
+	f.LaunchedContainers = append(f.LaunchedContainers, cfg)
+
+	id := "fake-" + strconv.Itoa(len(f.LaunchedContainers))
+	return &id, nil
+}
+
+func (f *FakeContainerLauncherUsecase) StopContainer(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.StopContainerErr != nil {
+		return f.StopContainerErr
+	}
+	f.StoppedContainers = append(f.StoppedContainers, id)
+
+	return nil
+}
+
+func (f *FakeContainerLauncherUsecase) RemoveContainer(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.RemoveContainerErr != nil {
+		return f.RemoveContainerErr
+	}
+	f.RemovedContainers = append(f.RemovedContainers, id)
+
+	return nil
+}
+
+func (f *FakeContainerLauncherUsecase) RemoveVolume(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.RemovedVolumes = append(f.RemovedVolumes, name)
+
+	return nil
+}
+
+func (f *FakeContainerLauncherUsecase) GetStorageDriver() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.StorageDriver == "" {
+		return "fake", nil
+	}
+	return f.StorageDriver, nil
+}
+
+func (f *FakeContainerLauncherUsecase) GetEngineInfo() (*domain.EngineInfo, error) {
+	storageDriver, err := f.GetStorageDriver()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.EngineInfo{Name: "fake", Version: "0.0.0", StorageDriver: storageDriver, CgroupVersion: "2"}, nil
+}
+
+func (f *FakeContainerLauncherUsecase) CreateNetwork(name string) (*string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.CreateNetworkErr != nil {
+		return nil, f.CreateNetworkErr
+	}
+	f.CreatedNetworks = append(f.CreatedNetworks, name)
+
+	return &name, nil
+}
+
+func (f *FakeContainerLauncherUsecase) RemoveNetwork(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.RemovedNetworks = append(f.RemovedNetworks, id)
+
+	return nil
+}
+
+// GetContainerStats returns stats from GetContainerStatsFunc or empty stats if it isn't set
+func (f *FakeContainerLauncherUsecase) GetContainerStats(id string) (*domain.ContainerStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.GetContainerStatsErr != nil {
+		return nil, f.GetContainerStatsErr
+	}
+
+	call := f.GetStatsCallsCount
+	f.GetStatsCallsCount++
+
+	if f.GetContainerStatsFunc != nil {
+		return f.GetContainerStatsFunc(id, call)
+	}
+	return &domain.ContainerStats{}, nil
+}
+
+// GetOomKillsCount returns count from OomKillsCountFunc or zero if it isn't set
+func (f *FakeContainerLauncherUsecase) GetOomKillsCount(id string) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	call := f.GetOomKillsCallsCount
+	f.GetOomKillsCallsCount++
+
+	if f.OomKillsCountFunc != nil {
+		return f.OomKillsCountFunc(id, call), nil
+	}
+	return 0, nil
+}
+
+// GetContainerStatsStream sends single stats snapshot and closes the channel
+func (f *FakeContainerLauncherUsecase) GetContainerStatsStream(id string) (<-chan *domain.ContainerStats, context.CancelFunc, error) {
+	stats, err := f.GetContainerStats(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statsCh := make(chan *domain.ContainerStats, 1)
+	statsCh <- stats
+	close(statsCh)
+
+	return statsCh, func() {}, nil
+}
@@ -0,0 +1,529 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	CGROUP_V2_ROOT           = "/sys/fs/cgroup"
+	NERDCTL_STATS_STREAM_GAP = time.Second
+	// Error message of image inspect, if the image isn't present locally
+	NERDCTL_NO_SUCH_IMAGE_MESSAGE = "no such image"
+)
+
+// nerdctlContainerLauncherUsecase launches containers in containerd via nerdctl CLI.
+// Stats are read from the container cgroup v2 and network namespace directly.
+type nerdctlContainerLauncherUsecase struct {
+	nerdctlPath string
+}
+
+type nerdctlImageInspect struct {
+	RepoDigests []string
+	Size        int64
+	RootFS      struct {
+		Layers []string
+	}
+}
+
+func NewNerdctlContainerLauncherUsecase(nerdctlPath string) (ContainerLauncherUsecase, error) {
+	cluc := new(nerdctlContainerLauncherUsecase)
+
+	path, err := exec.LookPath(nerdctlPath)
+	if err != nil {
+		return nil, err
+	}
+	cluc.nerdctlPath = path
+
+	return cluc, nil
+}
+
+func (cluc *nerdctlContainerLauncherUsecase) PullImage(image string, pullPolicy domain.PullPolicy) (*domain.ImageInfo, error) {
+	imageInfo := &domain.ImageInfo{Image: image}
+
+	switch pullPolicy {
+
+	case domain.PullPolicy_Always:
+		imageInfo.Pulled = true
+
+	case domain.PullPolicy_IfNotPresent, domain.PullPolicy_Never:
+		if _, err := cluc.inspectImage(image); err == domain.IMAGE_NOT_PRESENT_LOCALLY {
+			if pullPolicy == domain.PullPolicy_Never {
+				return nil, err
+			}
+			imageInfo.Pulled = true
+		} else if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, domain.UNKNOWN_PULL_POLICY
+	}
+
+	if imageInfo.Pulled {
+		startTime := time.Now()
+		// nerdctl reads registry credentials from Docker config file itself
+		if _, err := cluc.run("pull", "--quiet", image); err != nil {
+			return nil, err
+		}
+		imageInfo.PullDurationInMicros = time.Since(startTime).Microseconds()
+		logrus.WithFields(logrus.Fields{"image": image, "duration": time.Since(startTime)}).Debug("container image pulled")
+	}
+
+	inspect, err := cluc.inspectImage(image)
+	if err != nil {
+		return nil, err
+	}
+	imageInfo.SizeInBytes = inspect.Size
+	imageInfo.LayersCount = len(inspect.RootFS.Layers)
+	if len(inspect.RepoDigests) > 0 {
+		imageInfo.Digest = inspect.RepoDigests[0]
+	}
+
+	return imageInfo, nil
+}
+
+func (cluc *nerdctlContainerLauncherUsecase) LaunchContainer(cfg *domain.ContainerConfig) (*string, error) {
+	logrus.WithFields(logrus.Fields{"image": cfg.Image, "envVarMap": cfg.EnvVars, "port": cfg.Port}).Debug("launch container")
+
+	args := []string{"run", "--detach"}
+	if cfg.NetworkMode == domain.NetworkMode_Host {
+		args = append(args, "--network", domain.NetworkMode_Host)
+	} else if cfg.HostPort != 0 {
+		args = append(args, "--publish", "0.0.0.0:"+strconv.FormatUint(uint64(cfg.HostPort), 10)+":"+strconv.FormatUint(uint64(cfg.Port), 10))
+	}
+	if cfg.Network != "" && cfg.NetworkMode != domain.NetworkMode_Host {
+		// nerdctl resolves containers in the same network by names, so alias is used as a name prefix and hostname
+		args = append(args, "--network", cfg.Network, "--name", cfg.Network+"-"+cfg.Alias, "--hostname", cfg.Alias)
+	}
+	if cfg.Resources.Cpus > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(cfg.Resources.Cpus, 'f', -1, 64))
+	}
+	if cfg.Resources.MemoryInBytes > 0 {
+		args = append(args, "--memory", strconv.FormatInt(cfg.Resources.MemoryInBytes, 10))
+	}
+	for _, m := range cfg.Mounts {
+		mountArgs, err := cluc.convertMount(m)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, mountArgs...)
+	}
+	for _, envVar := range convertEnvVarsMapToSlice(cfg.EnvVars) {
+		args = append(args, "--env", envVar)
+	}
+	args = append(args, cfg.Image)
+	args = append(args, cfg.Cmd...)
+
+	out, err := cluc.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	id := strings.TrimSpace(string(out))
+	logrus.WithFields(logrus.Fields{"image": cfg.Image, "id": id}).Debug("container started")
+
+	return &id, nil
+}
+
+// GetStorageDriver returns containerd snapshotter used by nerdctl
+func (cluc *nerdctlContainerLauncherUsecase) GetStorageDriver() (string, error) {
+	out, err := cluc.run("info", "--format", "{{.Driver}}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetEngineInfo returns containerd info. nerdctl info format is compatible with Docker one
+func (cluc *nerdctlContainerLauncherUsecase) GetEngineInfo() (*domain.EngineInfo, error) {
+	out, err := cluc.run("info", "--format", "{{json .}}")
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		ServerVersion string
+		Driver        string
+		CgroupVersion string
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil, err
+	}
+
+	return &domain.EngineInfo{
+		Name:          "containerd",
+		Version:       info.ServerVersion,
+		StorageDriver: info.Driver,
+		CgroupVersion: info.CgroupVersion,
+	}, nil
+}
+
+func (cluc *nerdctlContainerLauncherUsecase) CreateNetwork(name string) (*string, error) {
+	if _, err := cluc.run("network", "create", name); err != nil {
+		return nil, err
+	}
+	logrus.WithField("name", name).Debug("network created")
+
+	return &name, nil
+}
+
+func (cluc *nerdctlContainerLauncherUsecase) RemoveNetwork(id string) error {
+	if _, err := cluc.run("network", "rm", id); err != nil {
+		return err
+	}
+	logrus.WithField("id", id).Debug("network removed")
+
+	return nil
+}
+
+func (cluc *nerdctlContainerLauncherUsecase) StopContainer(id string) error {
+	if _, err := cluc.run("stop", "--time", strconv.Itoa(int(STOP_CONTAINER_TIMEOUT.Seconds())), id); err != nil {
+		return err
+	}
+	logrus.WithField("id", id).Debug("container stopped")
+
+	return nil
+}
+
+func (cluc *nerdctlContainerLauncherUsecase) RemoveContainer(id string) error {
+	if _, err := cluc.run("rm", "--volumes", id); err != nil {
+		return err
+	}
+	logrus.WithField("id", id).Debug("container removed")
+
+	return nil
+}
+
+func (cluc *nerdctlContainerLauncherUsecase) RemoveVolume(name string) error {
+	if _, err := cluc.run("volume", "rm", name); err != nil && !strings.Contains(err.Error(), "not found") {
+		return err
+	}
+	logrus.WithField("name", name).Debug("volume removed")
+
+	return nil
+}
+
+func (cluc *nerdctlContainerLauncherUsecase) GetContainerStats(id string) (*domain.ContainerStats, error) {
+	out, err := cluc.run("inspect", "--format", "{{.State.Pid}}", id)
+	if err != nil {
+		return nil, err
+	}
+	pid := strings.TrimSpace(string(out))
+
+	cgroupPath, err := cluc.readCgroupPath(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.ContainerStats{Read: time.Now()}
+
+	cpuStat, err := cluc.readKeyValueFile(filepath.Join(cgroupPath, "cpu.stat"))
+	if err != nil {
+		return nil, err
+	}
+	stats.CpuStats = domain.ContainerCpuStats{
+		TotalUsage:  cpuStat["usage_usec"] * uint64(time.Microsecond),
+		UserUsage:   cpuStat["user_usec"] * uint64(time.Microsecond),
+		SystemUsage: cpuStat["system_usec"] * uint64(time.Microsecond),
+		// Cgroup files are read locally, so containers run on the same host
+		OnlineCpus:       uint32(runtime.NumCPU()),
+		ThrottledPeriods: cpuStat["nr_throttled"],
+		ThrottledTime:    cpuStat["throttled_usec"] * uint64(time.Microsecond),
+	}
+
+	if stats.MemoryStats, err = cluc.readMemoryStats(cgroupPath); err != nil {
+		return nil, err
+	}
+
+	if stats.BlkioStats, err = cluc.readIoStat(filepath.Join(cgroupPath, "io.stat")); err != nil {
+		return nil, err
+	}
+
+	if stats.Networks, err = readNetDev(filepath.Join("/proc", pid, "net", "dev")); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetOomKillsCount reads oom_kill counter of the container cgroup
+func (cluc *nerdctlContainerLauncherUsecase) GetOomKillsCount(id string) (uint64, error) {
+	out, err := cluc.run("inspect", "--format", "{{.State.Pid}}", id)
+	if err != nil {
+		return 0, err
+	}
+
+	cgroupPath, err := cluc.readCgroupPath(strings.TrimSpace(string(out)))
+	if err != nil {
+		return 0, err
+	}
+
+	memoryEvents, err := cluc.readKeyValueFile(filepath.Join(cgroupPath, "memory.events"))
+	if err != nil {
+		return 0, err
+	}
+	return memoryEvents["oom_kill"], nil
+}
+
+func (cluc *nerdctlContainerLauncherUsecase) GetContainerStatsStream(id string) (<-chan *domain.ContainerStats, context.CancelFunc, error) {
+	ctx, ctxCancelFunc := context.WithCancel(context.Background())
+
+	statsCh := make(chan *domain.ContainerStats, 1)
+
+	// Goroutine for polling stats, because cgroup files have no stream API
+	go func() {
+		defer close(statsCh)
+
+		ticker := time.NewTicker(NERDCTL_STATS_STREAM_GAP)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				logrus.WithField("id", id).Debug("stop logging container stats. context done")
+				return
+			case <-ticker.C:
+				stats, err := cluc.GetContainerStats(id)
+				if err != nil {
+					logrus.WithError(err).WithField("id", id).Debug("stop logging container stats")
+					return
+				}
+				statsCh <- stats
+			}
+		}
+	}()
+
+	return statsCh, ctxCancelFunc, nil
+}
+
+// inspectImage returns IMAGE_NOT_PRESENT_LOCALLY if the image isn't found, other errors are returned as is
+func (cluc *nerdctlContainerLauncherUsecase) inspectImage(image string) (*nerdctlImageInspect, error) {
+	out, err := cluc.run("image", "inspect", image)
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), NERDCTL_NO_SUCH_IMAGE_MESSAGE) {
+		return nil, domain.IMAGE_NOT_PRESENT_LOCALLY
+	} else if err != nil {
+		return nil, err
+	}
+
+	var inspects []nerdctlImageInspect
+	if err := json.Unmarshal(out, &inspects); err != nil {
+		return nil, err
+	}
+	if len(inspects) == 0 {
+		return nil, domain.IMAGE_NOT_PRESENT_LOCALLY
+	}
+
+	return &inspects[0], nil
+}
+
+// convertMount returns nerdctl run arguments for the mount. Only local volume driver is supported
+func (cluc *nerdctlContainerLauncherUsecase) convertMount(m domain.Mount) ([]string, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch m.Type {
+	case domain.MountType_Volume:
+		if m.GetDriver() != domain.DEFAULT_VOLUME_DRIVER || len(m.DriverOptions) > 0 {
+			return nil, domain.UNSUPPORTED_VOLUME_DRIVER
+		}
+		if m.Source == "" {
+			return []string{"--volume", m.Target}, nil
+		}
+		return []string{"--volume", m.Source + ":" + m.Target}, nil
+
+	case domain.MountType_Bind:
+		if m.ReadOnly {
+			return []string{"--volume", m.Source + ":" + m.Target + ":ro"}, nil
+		}
+		return []string{"--volume", m.Source + ":" + m.Target}, nil
+
+	default:
+		tmpfs := m.Target
+		if m.TmpfsSizeInBytes > 0 {
+			tmpfs += ":size=" + strconv.FormatInt(m.TmpfsSizeInBytes, 10)
+		}
+		return []string{"--tmpfs", tmpfs}, nil
+	}
+}
+
+func (cluc *nerdctlContainerLauncherUsecase) run(args ...string) ([]byte, error) {
+	logrus.WithField("args", args).Trace("run nerdctl")
+
+	out, err := exec.Command(cluc.nerdctlPath, args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// readCgroupPath returns cgroup v2 directory of the process
+func (cluc *nerdctlContainerLauncherUsecase) readCgroupPath(pid string) (string, error) {
+	content, err := os.ReadFile(filepath.Join("/proc", pid, "cgroup"))
+	if err != nil {
+		return "", err
+	}
+
+	// cgroup v2 has the only line "0::<path>"
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "0::") {
+			return filepath.Join(CGROUP_V2_ROOT, strings.TrimPrefix(line, "0::")), nil
+		}
+	}
+
+	return "", domain.UNSUPPORTED_CGROUP_VERSION
+}
+
+// readKeyValueFile parses cgroup files with "key value" lines
+func (cluc *nerdctlContainerLauncherUsecase) readKeyValueFile(path string) (map[string]uint64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if value, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = value
+		}
+	}
+
+	return values, nil
+}
+
+// readMemoryStats reads cgroup v2 memory controller files
+func (cluc *nerdctlContainerLauncherUsecase) readMemoryStats(cgroupPath string) (domain.ContainerMemoryStats, error) {
+	var stats domain.ContainerMemoryStats
+
+	var err error
+	if stats.Usage, err = cluc.readUintFile(filepath.Join(cgroupPath, "memory.current")); err != nil {
+		return stats, err
+	}
+
+	memoryStat, err := cluc.readKeyValueFile(filepath.Join(cgroupPath, "memory.stat"))
+	if err != nil {
+		return stats, err
+	}
+	stats.Rss = memoryStat["anon"]
+	stats.Cache = memoryStat["file"]
+	stats.InactiveFile = memoryStat["inactive_file"]
+	stats.PageFaults = memoryStat["pgfault"]
+	stats.MajorPageFaults = memoryStat["pgmajfault"]
+
+	// memory.peak appeared in Linux 5.19
+	if peak, err := cluc.readUintFile(filepath.Join(cgroupPath, "memory.peak")); err == nil {
+		stats.MaxUsage = peak
+	}
+
+	// memory.max is "max" if unlimited. Host memory is used then like Docker does
+	if limit, err := cluc.readUintFile(filepath.Join(cgroupPath, "memory.max")); err == nil {
+		stats.Limit = limit
+	} else if memTotal, err := cluc.readMemTotal("/proc/meminfo"); err == nil {
+		stats.Limit = memTotal
+	}
+
+	return stats, nil
+}
+
+// readMemTotal returns host memory size in bytes from "MemTotal: 6147400 kB" line of /proc/meminfo
+func (cluc *nerdctlContainerLauncherUsecase) readMemTotal(path string) (uint64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 3 && fields[0] == "MemTotal:" {
+			value, err := strconv.ParseUint(fields[1], 10, 64)
+			return value * 1024, err
+		}
+	}
+
+	return 0, io.EOF
+}
+
+// readUintFile reads file with the single number
+func (cluc *nerdctlContainerLauncherUsecase) readUintFile(path string) (uint64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// readIoStat parses cgroup v2 io.stat with "major:minor rbytes=1 wbytes=2 ..." lines
+func (cluc *nerdctlContainerLauncherUsecase) readIoStat(path string) (domain.ContainerBlkioStats, error) {
+	var stats domain.ContainerBlkioStats
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return stats, nil
+	} else if err != nil {
+		return stats, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		device := strings.SplitN(fields[0], ":", 2)
+		if len(device) != 2 {
+			continue
+		}
+		major, _ := strconv.ParseUint(device[0], 10, 64)
+		minor, _ := strconv.ParseUint(device[1], 10, 64)
+
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			value, err := strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				continue
+			}
+
+			switch kv[0] {
+			case "rbytes":
+				stats.IoServiceBytesRecursive = append(stats.IoServiceBytesRecursive, domain.ContainerBlkioStatEntry{Major: major, Minor: minor, Op: domain.BlkioOp_Read, Value: value})
+			case "wbytes":
+				stats.IoServiceBytesRecursive = append(stats.IoServiceBytesRecursive, domain.ContainerBlkioStatEntry{Major: major, Minor: minor, Op: domain.BlkioOp_Write, Value: value})
+			case "rios":
+				stats.IoServicedRecursive = append(stats.IoServicedRecursive, domain.ContainerBlkioStatEntry{Major: major, Minor: minor, Op: domain.BlkioOp_Read, Value: value})
+			case "wios":
+				stats.IoServicedRecursive = append(stats.IoServicedRecursive, domain.ContainerBlkioStatEntry{Major: major, Minor: minor, Op: domain.BlkioOp_Write, Value: value})
+			}
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+func TestNerdctlContainerLauncherUsecase_ReadIoStat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "io.stat")
+	content := "8:0 rbytes=4096 wbytes=8192 rios=1 wios=2 dbytes=0 dios=0\n8:16 rbytes=512 wbytes=0 rios=3 wios=0 dbytes=0 dios=0\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := new(nerdctlContainerLauncherUsecase).readIoStat(path)
+	if err != nil {
+		t.Fatalf("readIoStat() error = %v", err)
+	}
+
+	devices := stats.Devices()
+	if len(devices) != 2 {
+		t.Fatalf("devices = %v, want 8:0 and 8:16", devices)
+	}
+	if d := devices["8:0"]; d.ReadBytes != 4096 || d.WriteBytes != 8192 || d.ReadOperations != 1 || d.WriteOperations != 2 {
+		t.Errorf("8:0 = %+v", d)
+	}
+	if total := stats.Total(); total.ReadBytes != 4608 || total.ReadOperations != 4 {
+		t.Errorf("total = %+v, want 4608 read bytes and 4 read operations", total)
+	}
+
+	// Missing io.stat means no IO controller in the cgroup
+	if stats, err := new(nerdctlContainerLauncherUsecase).readIoStat(filepath.Join(t.TempDir(), "missing")); err != nil || len(stats.IoServiceBytesRecursive) != 0 {
+		t.Errorf("readIoStat() of missing file = %v, %v, want empty stats", stats, err)
+	}
+}
+
+func TestNerdctlContainerLauncherUsecase_ReadMemoryStats(t *testing.T) {
+	cgroupPath := t.TempDir()
+	files := map[string]string{
+		"memory.current": "8192\n",
+		"memory.stat":    "anon 2048\nfile 6144\ninactive_file 4096\npgfault 25\npgmajfault 3\n",
+		"memory.max":     "16384\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(cgroupPath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := new(nerdctlContainerLauncherUsecase).readMemoryStats(cgroupPath)
+	if err != nil {
+		t.Fatalf("readMemoryStats() error = %v", err)
+	}
+
+	// memory.peak is missing on old kernels
+	expected := domain.ContainerMemoryStats{Usage: 8192, Limit: 16384, Rss: 2048, Cache: 6144, InactiveFile: 4096, PageFaults: 25, MajorPageFaults: 3}
+	if stats != expected {
+		t.Errorf("stats = %+v, want %+v", stats, expected)
+	}
+	if stats.WorkingSet() != 4096 {
+		t.Errorf("working set = %d, want 4096", stats.WorkingSet())
+	}
+}
+
+func TestNerdctlContainerLauncherUsecase_PullImage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake nerdctl script requires sh")
+	}
+
+	dir := t.TempDir()
+	// Fake nerdctl finds "missing" image only after pull and can't inspect "broken" one
+	script := "#!/bin/sh\n" +
+		"case \"$1 $2 $3\" in\n" +
+		"\"image inspect present\") echo '[{\"Size\":10,\"RootFS\":{\"Layers\":[\"a\"]}}]' ;;\n" +
+		"\"image inspect missing\") if [ -f " + dir + "/pulled ]; then echo '[{\"Size\":20}]'; else echo 'time=\"now\" level=fatal msg=\"1 errors:\\nno such image: missing\"' >&2; exit 1; fi ;;\n" +
+		"\"image inspect broken\") echo 'permission denied' >&2; exit 1 ;;\n" +
+		"pull*) touch " + dir + "/pulled ;;\n" +
+		"esac\n"
+	nerdctlPath := filepath.Join(dir, "nerdctl")
+	if err := ioutil.WriteFile(nerdctlPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		image       string
+		pullPolicy  domain.PullPolicy
+		expectedErr error
+		// Error of the fake nerdctl is returned as is
+		expectRunErr   bool
+		expectedPulled bool
+	}{
+		{name: "present", image: "present", pullPolicy: domain.PullPolicy_IfNotPresent},
+		{name: "missing with never policy", image: "missing", pullPolicy: domain.PullPolicy_Never, expectedErr: domain.IMAGE_NOT_PRESENT_LOCALLY},
+		{name: "missing", image: "missing", pullPolicy: domain.PullPolicy_IfNotPresent, expectedPulled: true},
+		{name: "inspect error isn't treated as missing image", image: "broken", pullPolicy: domain.PullPolicy_IfNotPresent, expectRunErr: true},
+	}
+
+	cluc, err := NewNerdctlContainerLauncherUsecase(nerdctlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(filepath.Join(dir, "pulled"))
+
+			imageInfo, err := cluc.PullImage(tt.image, tt.pullPolicy)
+			if tt.expectRunErr {
+				if err == nil || err == domain.IMAGE_NOT_PRESENT_LOCALLY {
+					t.Fatalf("PullImage() error = %v, want inspect error", err)
+				}
+			} else if err != tt.expectedErr {
+				t.Fatalf("PullImage() error = %v, want %v", err, tt.expectedErr)
+			}
+			if imageInfo != nil && imageInfo.Pulled != tt.expectedPulled {
+				t.Errorf("pulled = %v, want %v", imageInfo.Pulled, tt.expectedPulled)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "pulled")); (err == nil) != tt.expectedPulled {
+				t.Errorf("pull was called = %v, want %v", err == nil, tt.expectedPulled)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+// readNetDev parses /proc/<pid>/net/dev of the container network namespace. Loopback interface is included
+func readNetDev(path string) (map[string]domain.ContainerNetworkStats, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseNetDev(content), nil
+}
+
+func parseNetDev(content []byte) map[string]domain.ContainerNetworkStats {
+	networks := make(map[string]domain.ContainerNetworkStats)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			// Header lines
+			continue
+		}
+
+		// Receive: bytes packets errs drop fifo frame compressed multicast
+		// Transmit: bytes packets errs drop fifo colls carrier compressed
+		fields := strings.Fields(parts[1])
+		if len(fields) < 16 {
+			continue
+		}
+		values := make([]uint64, len(fields))
+		for i, field := range fields {
+			values[i], _ = strconv.ParseUint(field, 10, 64)
+		}
+
+		networks[strings.TrimSpace(parts[0])] = domain.ContainerNetworkStats{
+			RxBytes:   values[0],
+			RxPackets: values[1],
+			RxErrors:  values[2],
+			RxDropped: values[3],
+			TxBytes:   values[8],
+			TxPackets: values[9],
+			TxErrors:  values[10],
+			TxDropped: values[11],
+		}
+	}
+
+	return networks
+}
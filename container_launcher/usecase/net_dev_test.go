@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+func TestParseNetDev(t *testing.T) {
+	content := []byte(`Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:     120       2    0    0    0     0          0         0      120       2    0    0    0     0       0          0
+  eth0:    1500      10    1    2    0     0          0         0      900       8    3    4    0     0       0          0
+`)
+
+	networks := parseNetDev(content)
+	if len(networks) != 2 {
+		t.Fatalf("networks = %v, want lo and eth0", networks)
+	}
+
+	expected := domain.ContainerNetworkStats{RxBytes: 1500, RxPackets: 10, RxErrors: 1, RxDropped: 2, TxBytes: 900, TxPackets: 8, TxErrors: 3, TxDropped: 4}
+	if networks["eth0"] != expected {
+		t.Errorf("eth0 = %+v, want %+v", networks["eth0"], expected)
+	}
+	if networks["lo"].RxBytes != 120 {
+		t.Errorf("lo receive bytes = %d, want 120", networks["lo"].RxBytes)
+	}
+}
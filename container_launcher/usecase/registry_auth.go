@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DOCKER_HUB_DOMAIN         = "docker.io"
+	DOCKER_HUB_AUTH_KEY       = "https://index.docker.io/v1/"
+	DOCKER_HUB_INDEX_DOMAIN   = "index.docker.io"
+	DOCKER_CONFIG_DIR_ENV_VAR = "DOCKER_CONFIG"
+	DOCKER_CONFIG_FILE_NAME   = "config.json"
+
+	CREDENTIAL_HELPER_PREFIX      = "docker-credential-"
+	CREDENTIALS_NOT_FOUND_MESSAGE = "credentials not found"
+	// Username of the credential helper response with the identity token in the secret
+	IDENTITY_TOKEN_USERNAME = "<token>"
+)
+
+type dockerConfigFile struct {
+	Auths map[string]types.AuthConfig `json:"auths"`
+	// Credential helper for all registries, e.g. desktop or pass
+	CredsStore string `json:"credsStore"`
+	// Credential helpers by registry domain
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// credentialHelperOutput is the "get" command response of the Docker credential helper
+type credentialHelperOutput struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// createRegistryAuth returns encoded credentials for the image registry.
+// Returns empty string if there are no credentials, so anonymous pull is used.
+func (cluc *dockerContainerLauncherUsecase) createRegistryAuth(image string) (string, error) {
+	authConfig, err := cluc.findAuthConfig(image)
+	if err != nil {
+		return "", err
+	}
+	if authConfig == nil {
+		return "", nil
+	}
+
+	authConfigBytes, err := json.Marshal(authConfig)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.URLEncoding.EncodeToString(authConfigBytes), nil
+}
+
+// findAuthConfig returns credentials of the image registry with Docker priority:
+// env vars for the configured server, then credHelpers, credsStore and auths entries of Docker config file
+func (cluc *dockerContainerLauncherUsecase) findAuthConfig(image string) (*types.AuthConfig, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, err
+	}
+	registry := reference.Domain(named)
+
+	if cluc.registryCfg.Username != "" && cluc.normalizeRegistry(cluc.registryCfg.Server) == registry {
+		return &types.AuthConfig{Username: cluc.registryCfg.Username, Password: cluc.registryCfg.Password, ServerAddress: cluc.registryCfg.Server}, nil
+	}
+
+	dockerConfig, err := cluc.readDockerConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	if dockerConfig == nil {
+		return nil, nil
+	}
+
+	if helper, ok := dockerConfig.CredHelpers[registry]; ok {
+		return cluc.getHelperCredentials(helper, registry)
+	}
+	if dockerConfig.CredsStore != "" {
+		authConfig, err := cluc.getHelperCredentials(dockerConfig.CredsStore, registry)
+		if err != nil || authConfig != nil {
+			return authConfig, err
+		}
+	}
+
+	for key, authConfig := range dockerConfig.Auths {
+		if cluc.normalizeRegistry(key) != registry {
+			continue
+		}
+
+		// Credentials are stored in the "auth" field as base64 encoded "user:password"
+		if authConfig.Auth != "" && authConfig.Username == "" {
+			decoded, err := base64.StdEncoding.DecodeString(authConfig.Auth)
+			if err != nil {
+				return nil, err
+			}
+			if parts := strings.SplitN(string(decoded), ":", 2); len(parts) == 2 {
+				authConfig.Username = parts[0]
+				authConfig.Password = parts[1]
+			}
+			authConfig.Auth = ""
+		}
+		authConfig.ServerAddress = key
+
+		logrus.WithFields(logrus.Fields{"image": image, "registry": registry}).Debug("found registry credentials")
+		return &authConfig, nil
+	}
+
+	return nil, nil
+}
+
+// getHelperCredentials gets registry credentials from docker-credential-<helper> binary.
+// Returns nil if the helper has no credentials for the registry.
+func (cluc *dockerContainerLauncherUsecase) getHelperCredentials(helper, registry string) (*types.AuthConfig, error) {
+	serverAddress := registry
+	if registry == DOCKER_HUB_DOMAIN {
+		serverAddress = DOCKER_HUB_AUTH_KEY
+	}
+
+	cmd := exec.Command(CREDENTIAL_HELPER_PREFIX+helper, "get")
+	cmd.Stdin = strings.NewReader(serverAddress)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// Helpers report missing credentials by the message with the non-zero exit code
+		if strings.Contains(stdout.String()+stderr.String(), CREDENTIALS_NOT_FOUND_MESSAGE) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("credential helper %s: %w: %s", helper, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var creds credentialHelperOutput
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{"helper": helper, "registry": registry}).Debug("found registry credentials in credential helper")
+	authConfig := &types.AuthConfig{ServerAddress: serverAddress}
+	if creds.Username == IDENTITY_TOKEN_USERNAME {
+		authConfig.IdentityToken = creds.Secret
+	} else {
+		authConfig.Username = creds.Username
+		authConfig.Password = creds.Secret
+	}
+	return authConfig, nil
+}
+
+func (cluc *dockerContainerLauncherUsecase) readDockerConfigFile() (*dockerConfigFile, error) {
+	filePath := cluc.registryCfg.DockerConfigFilePath
+	if filePath == "" {
+		if dir := os.Getenv(DOCKER_CONFIG_DIR_ENV_VAR); dir != "" {
+			filePath = filepath.Join(dir, DOCKER_CONFIG_FILE_NAME)
+		} else if home, err := os.UserHomeDir(); err == nil {
+			filePath = filepath.Join(home, ".docker", DOCKER_CONFIG_FILE_NAME)
+		} else {
+			return nil, nil
+		}
+	}
+
+	configBytes, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		logrus.WithField("filePath", filePath).Debug("docker config file not found")
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var dockerConfig dockerConfigFile
+	if err := json.Unmarshal(configBytes, &dockerConfig); err != nil {
+		return nil, err
+	}
+
+	return &dockerConfig, nil
+}
+
+// normalizeRegistry converts Docker config auth key into registry domain
+func (cluc *dockerContainerLauncherUsecase) normalizeRegistry(key string) string {
+	if key == DOCKER_HUB_AUTH_KEY {
+		return DOCKER_HUB_DOMAIN
+	}
+
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+	key = strings.SplitN(key, "/", 2)[0]
+	if key == DOCKER_HUB_INDEX_DOMAIN {
+		return DOCKER_HUB_DOMAIN
+	}
+	return key
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+func TestDockerContainerLauncherUsecase_FindAuthConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper script requires sh")
+	}
+
+	dir := t.TempDir()
+	// Fake helper returns credentials only for ghcr.io
+	helper := "#!/bin/sh\nread server\nif [ \"$server\" = ghcr.io ]; then echo '{\"ServerURL\":\"ghcr.io\",\"Username\":\"helper-user\",\"Secret\":\"helper-secret\"}'; else echo 'credentials not found in native keychain'; exit 1; fi\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "docker-credential-fake"), []byte(helper), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	configPath := filepath.Join(dir, "config.json")
+	dockerConfig := `{
+		"auths": {"https://index.docker.io/v1/": {"auth": "aHViLXVzZXI6aHViLXNlY3JldA=="}, "quay.io": {"username": "quay-user", "password": "quay-secret"}},
+		"credsStore": "fake",
+		"credHelpers": {"gcr.io": "fake"}
+	}`
+	if err := ioutil.WriteFile(configPath, []byte(dockerConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		image            string
+		expectedUsername string
+	}{
+		{name: "env credentials for the server", image: "registry.example.com:5000/app:1", expectedUsername: "env-user"},
+		{name: "env credentials aren't sent to docker hub", image: "postgres:10", expectedUsername: "hub-user"},
+		{name: "creds store", image: "ghcr.io/org/app", expectedUsername: "helper-user"},
+		{name: "creds store without credentials falls back to auths", image: "quay.io/org/app", expectedUsername: "quay-user"},
+		{name: "cred helper without credentials", image: "gcr.io/project/app", expectedUsername: ""},
+		{name: "no credentials", image: "example.org/app", expectedUsername: ""},
+	}
+
+	cluc := &dockerContainerLauncherUsecase{registryCfg: domain.RegistryConfig{
+		Server:               "registry.example.com:5000",
+		Username:             "env-user",
+		Password:             "env-secret",
+		DockerConfigFilePath: configPath,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authConfig, err := cluc.findAuthConfig(tt.image)
+			if err != nil {
+				t.Fatalf("findAuthConfig() error = %v", err)
+			}
+			username := ""
+			if authConfig != nil {
+				username = authConfig.Username
+			}
+			if username != tt.expectedUsername {
+				t.Errorf("username = %q, want %q", username, tt.expectedUsername)
+			}
+		})
+	}
+}
+
+func TestNewDockerContainerLauncherUsecase_RegistryServerIsRequired(t *testing.T) {
+	if _, err := NewDockerContainerLauncherUsecase("", domain.RegistryConfig{Username: "user"}); err != domain.REGISTRY_SERVER_IS_REQUIRED {
+		t.Errorf("NewDockerContainerLauncherUsecase() error = %v, want %v", err, domain.REGISTRY_SERVER_IS_REQUIRED)
+	}
+}
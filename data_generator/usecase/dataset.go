@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+	"github.com/sirupsen/logrus"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	// Rows count generated in memory at once while writing dataset file
+	DATASET_WRITE_BATCH_SIZE  = 10000
+	PARQUET_WRITE_PARALLELISM = 4
+)
+
+// DatasetFilePath returns path of the dataset file with count rows.
+// Path depends on the table config and seed, so the file could be reused by other test cases.
+func (dguc *dataGeneratorUsecase) DatasetFilePath(cfg *domain.DatasetConfig, count int) string {
+	return filepath.Join(cfg.GetDirectory(), dguc.fingerprint+"-"+strconv.Itoa(count)+"."+string(cfg.GetFormat()))
+}
+
+// WriteDataset generates count rows from the beginning of the seeded sequence into the file.
+// Existing file is reused. Generator state isn't changed.
+func (dguc *dataGeneratorUsecase) WriteDataset(cfg *domain.DatasetConfig, count int) (string, error) {
+	filePath := dguc.DatasetFilePath(cfg, count)
+	if _, err := os.Stat(filePath); err == nil {
+		return filePath, nil
+	}
+
+	if err := os.MkdirAll(cfg.GetDirectory(), 0755); err != nil {
+		return "", err
+	}
+
+	// Separate generator to get the same rows as inserts from the beginning of the sequence
+	generator, err := NewDataGeneratorUsecase(dguc.cfg, dguc.seed)
+	if err != nil {
+		return "", err
+	}
+
+	startTime := time.Now()
+	// Write to temporary file to prevent reuse of partially written file
+	tmpFilePath := filePath + ".tmp"
+	switch cfg.GetFormat() {
+	case domain.DatasetFormat_Csv:
+		err = writeCsvDataset(generator, tmpFilePath, count)
+	case domain.DatasetFormat_Parquet:
+		err = writeParquetDataset(generator, tmpFilePath, count)
+	default:
+		err = domain.UNKNOWN_DATASET_FORMAT
+	}
+	if err != nil {
+		os.Remove(tmpFilePath)
+		return "", err
+	}
+	if err := os.Rename(tmpFilePath, filePath); err != nil {
+		return "", err
+	}
+	logrus.WithFields(logrus.Fields{"filePath": filePath, "count": count, "duration": time.Since(startTime)}).Debug("dataset generated")
+
+	return filePath, nil
+}
+
+func writeCsvDataset(generator DataGeneratorUsecase, filePath string, count int) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write(generator.Columns()); err != nil {
+		return err
+	}
+
+	record := make([]string, len(generator.Columns()))
+	for written := 0; written < count; written += DATASET_WRITE_BATCH_SIZE {
+		for _, row := range generator.Generate(min(DATASET_WRITE_BATCH_SIZE, count-written)) {
+			for i, column := range generator.Columns() {
+				record[i] = formatValue(row[column])
+			}
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeParquetDataset writes values as UTF8 strings, which are parsed by the database on load
+func writeParquetDataset(generator DataGeneratorUsecase, filePath string, count int) error {
+	f, err := local.NewLocalFileWriter(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var md []string
+	for _, column := range generator.Columns() {
+		md = append(md, "name="+column+", type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REQUIRED")
+	}
+	pw, err := writer.NewCSVWriter(md, f, PARQUET_WRITE_PARALLELISM)
+	if err != nil {
+		return err
+	}
+
+	for written := 0; written < count; written += DATASET_WRITE_BATCH_SIZE {
+		for _, row := range generator.Generate(min(DATASET_WRITE_BATCH_SIZE, count-written)) {
+			record := make([]*string, len(generator.Columns()))
+			for i, column := range generator.Columns() {
+				value := formatValue(row[column])
+				record[i] = &value
+			}
+			if err := pw.WriteString(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := pw.WriteStop(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// RowsSize returns size of the rows values in the text format. It is used as the payload size of inserts
+func RowsSize(rows []map[string]interface{}) int64 {
+	var size int64
+	for _, row := range rows {
+		for _, v := range row {
+			size += int64(len(formatValue(v)))
+		}
+	}
+	return size
+}
+
+// formatValue formats value in the text representation accepted by the database
+func formatValue(v interface{}) string {
+	switch value := v.(type) {
+	case time.Time:
+		return value.Format("2006-01-02")
+	case []byte:
+		return `\x` + hex.EncodeToString(value)
+	case float32:
+		return strconv.FormatFloat(float64(value), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+const PARQUET_READ_PARALLELISM = 4
+
+// DatasetReader reads rows of the csv or parquet dataset file
+type DatasetReader interface {
+	Columns() []string
+	// Read returns next rows up to count. Returns io.EOF if there are no more rows
+	Read(count int) ([][]interface{}, error)
+	Close() error
+}
+
+type csvDatasetReader struct {
+	f       *os.File
+	r       *csv.Reader
+	columns []string
+}
+
+type parquetDatasetReader struct {
+	f         source.ParquetFile
+	pr        *reader.ParquetReader
+	columns   []string
+	remaining int64
+}
+
+// NewDatasetReader opens dataset file of the format. Csv file should have the header row with columns names
+func NewDatasetReader(filePath string, format domain.DatasetFormat) (DatasetReader, error) {
+	switch format {
+
+	case domain.DatasetFormat_Csv:
+		f, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		dr := &csvDatasetReader{f: f, r: csv.NewReader(f)}
+		dr.r.ReuseRecord = false
+		if dr.columns, err = dr.r.Read(); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return dr, nil
+
+	case domain.DatasetFormat_Parquet:
+		f, err := local.NewLocalFileReader(filePath)
+		if err != nil {
+			return nil, err
+		}
+		pr, err := reader.NewParquetColumnReader(f, PARQUET_READ_PARALLELISM)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		dr := &parquetDatasetReader{f: f, pr: pr, remaining: pr.GetNumRows()}
+		// The first schema element is the root
+		for i := 1; i < len(pr.SchemaHandler.Infos); i++ {
+			dr.columns = append(dr.columns, pr.SchemaHandler.GetExName(i))
+		}
+		return dr, nil
+
+	default:
+		return nil, domain.UNKNOWN_DATASET_FORMAT
+	}
+}
+
+func (dr *csvDatasetReader) Columns() []string {
+	return dr.columns
+}
+
+func (dr *csvDatasetReader) Read(count int) ([][]interface{}, error) {
+	rows := make([][]interface{}, 0, count)
+	for len(rows) < count {
+		record, err := dr.r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		row := make([]interface{}, len(record))
+		for i, value := range record {
+			row[i] = value
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, io.EOF
+	}
+	return rows, nil
+}
+
+func (dr *csvDatasetReader) Close() error {
+	return dr.f.Close()
+}
+
+func (dr *parquetDatasetReader) Columns() []string {
+	return dr.columns
+}
+
+func (dr *parquetDatasetReader) Read(count int) ([][]interface{}, error) {
+	if dr.remaining <= 0 {
+		return nil, io.EOF
+	}
+
+	num := int64(count)
+	if dr.remaining < num {
+		num = dr.remaining
+	}
+	dr.remaining -= num
+
+	rows := make([][]interface{}, num)
+	for i := range rows {
+		rows[i] = make([]interface{}, len(dr.columns))
+	}
+	for i := range dr.columns {
+		values, _, _, err := dr.pr.ReadColumnByIndex(int64(i), num)
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j < len(values) && j < len(rows); j++ {
+			rows[j][i] = values[j]
+		}
+	}
+
+	return rows, nil
+}
+
+func (dr *parquetDatasetReader) Close() error {
+	dr.pr.ReadStop()
+	return dr.f.Close()
+}
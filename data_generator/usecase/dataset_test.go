@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"io"
+	"testing"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+func TestDataGeneratorUsecase_WriteDataset(t *testing.T) {
+	tableCfg := &domain.TableConfig{Columns: []domain.ColumnConfig{
+		{Name: "u", Distribution: domain.ValueDistribution_Uniform},
+		{Name: "t", Distribution: domain.ValueDistribution_Text},
+		{Name: "b", Distribution: domain.ValueDistribution_Bytea, Length: 4},
+	}}
+
+	for _, format := range []domain.DatasetFormat{domain.DatasetFormat_Csv, domain.DatasetFormat_Parquet} {
+		t.Run(string(format), func(t *testing.T) {
+			cfg := &domain.DatasetConfig{Format: format, Directory: t.TempDir()}
+
+			dguc, err := NewDataGeneratorUsecase(tableCfg, 3)
+			if err != nil {
+				t.Fatalf("NewDataGeneratorUsecase() error = %v", err)
+			}
+			filePath, err := dguc.WriteDataset(cfg, 25)
+			if err != nil {
+				t.Fatalf("WriteDataset() error = %v", err)
+			}
+			if reusedFilePath, err := dguc.WriteDataset(cfg, 25); err != nil || reusedFilePath != filePath {
+				t.Errorf("WriteDataset() = %s, %v, want reused %s", reusedFilePath, err, filePath)
+			}
+
+			dr, err := NewDatasetReader(filePath, format)
+			if err != nil {
+				t.Fatalf("NewDatasetReader() error = %v", err)
+			}
+			defer dr.Close()
+			if columns := dr.Columns(); len(columns) != 3 || columns[0] != "u" || columns[2] != "b" {
+				t.Fatalf("columns = %v, want [u t b]", columns)
+			}
+
+			// Dataset should be the same as generated for inserts
+			expected := dguc.Generate(25)
+			var rows [][]interface{}
+			for {
+				batch, err := dr.Read(10)
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("Read() error = %v", err)
+				}
+				rows = append(rows, batch...)
+			}
+			if len(rows) != 25 {
+				t.Fatalf("rows count = %d, want 25", len(rows))
+			}
+			for i, row := range rows {
+				for j, column := range dr.Columns() {
+					if row[j] != formatValue(expected[i][column]) {
+						t.Errorf("row %d column %s = %v, want %v", i, column, row[j], formatValue(expected[i][column]))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRowsSize(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"id": 1, "name": "alice", "score": 1.5},
+		{"id": 22, "name": "bob", "data": []byte{0xab}},
+	}
+	// 1 + 5 + 3 and 2 + 3 + 4
+	if size := RowsSize(rows); size != 18 {
+		t.Errorf("RowsSize() = %d, want 18", size)
+	}
+}
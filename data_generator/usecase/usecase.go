@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+var (
+	defaultTableFields = []string{
+		"id BIGSERIAL PRIMARY KEY",
+		"f1 BIGINT",
+		"f2 BIGSERIAL",
+		"f3 BOOLEAN",
+		"f4 DATE",
+		"f5 FLOAT",
+		"f6 REAL",
+		"f7 INTEGER",
+		"f8 NUMERIC",
+		"f9 SMALLINT",
+		"f10 SMALLSERIAL",
+		"f11 SERIAL",
+	}
+	defaultTableColumns          = []string{"f1", "f2", "f3", "f4", "f5", "f6", "f7", "f8", "f9", "f10", "f11"}
+	defaultTableSelectConditions = "f1>1 AND f2>1 AND f3 AND F5>0.5 AND f6>0.5 AND f7>1 AND f8>1 AND f9>1 AND f10>1 AND f11>1"
+	// Dates are generated from the fixed day to get the same data on every run
+	defaultTableBaseDate = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// DataGeneratorUsecase generates rows of the test table.
+// Generators with the same seed and config return the same rows sequence.
+type DataGeneratorUsecase interface {
+	// Fields returns columns definitions for the table creation
+	Fields() []string
+	Columns() []string
+	// SelectConditions returns WHERE clause for the select by conditions test
+	SelectConditions() string
+	// Generate returns next count rows
+	Generate(count int) []map[string]interface{}
+	// WriteDataset writes the first count rows of the sequence into the file and returns its path
+	WriteDataset(cfg *domain.DatasetConfig, count int) (string, error)
+}
+
+type dataGeneratorUsecase struct {
+	cfg  *domain.TableConfig
+	seed int64
+	// Hash of the columns config and seed identifying generated rows sequence
+	fingerprint string
+
+	fields           []string
+	columns          []string
+	selectConditions string
+	generators       []valueGenerator
+}
+
+// NewDataGeneratorUsecase creates generator for the table config. Default 11 columns schema is used if columns aren't set
+func NewDataGeneratorUsecase(cfg *domain.TableConfig, seed int64) (DataGeneratorUsecase, error) {
+	dguc := new(dataGeneratorUsecase)
+	dguc.cfg = cfg
+	dguc.seed = seed
+	rng := rand.New(rand.NewSource(seed))
+
+	fingerprint := fnv.New64a()
+	if cfg != nil {
+		// Columns config consists of the marshalable types only
+		columnsJson, _ := json.Marshal(cfg.Columns)
+		fingerprint.Write(columnsJson)
+	}
+	fingerprint.Write([]byte(strconv.FormatInt(seed, 10)))
+	dguc.fingerprint = strconv.FormatUint(fingerprint.Sum64(), 16)
+
+	if cfg == nil || len(cfg.Columns) == 0 {
+		dguc.fields = defaultTableFields
+		dguc.columns = defaultTableColumns
+		dguc.selectConditions = defaultTableSelectConditions
+		dguc.generators = defaultTableGenerators(rng)
+	} else {
+		dguc.fields = []string{"id BIGSERIAL PRIMARY KEY"}
+		var conditions []string
+		for i := range cfg.Columns {
+			column := cfg.Columns[i]
+			if err := column.Validate(); err != nil {
+				return nil, err
+			}
+
+			dguc.fields = append(dguc.fields, column.Name+" "+column.GetType())
+			dguc.columns = append(dguc.columns, column.Name)
+			dguc.generators = append(dguc.generators, newValueGenerator(rng, &column))
+			if column.IsInteger() {
+				conditions = append(conditions, column.Name+">"+strconv.FormatInt(column.Min+(column.GetMax()-column.Min)/2, 10))
+			}
+		}
+
+		if len(conditions) > 0 {
+			dguc.selectConditions = strings.Join(conditions, " AND ")
+		} else {
+			dguc.selectConditions = "TRUE"
+		}
+	}
+
+	if cfg != nil && cfg.SelectConditions != "" {
+		dguc.selectConditions = cfg.SelectConditions
+	}
+
+	return dguc, nil
+}
+
+func (dguc *dataGeneratorUsecase) Fields() []string {
+	return dguc.fields
+}
+
+func (dguc *dataGeneratorUsecase) Columns() []string {
+	return dguc.columns
+}
+
+func (dguc *dataGeneratorUsecase) SelectConditions() string {
+	return dguc.selectConditions
+}
+
+func (dguc *dataGeneratorUsecase) Generate(count int) []map[string]interface{} {
+	values := make([]map[string]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		valuesSet := make(map[string]interface{}, len(dguc.generators))
+		// Values are generated in columns order to keep the sequence reproducible
+		for j, generator := range dguc.generators {
+			valuesSet[dguc.columns[j]] = generator()
+		}
+		values = append(values, valuesSet)
+	}
+	return values
+}
+
+// defaultTableGenerators returns generators of the default schema columns
+func defaultTableGenerators(rng *rand.Rand) []valueGenerator {
+	smallInt := func() interface{} { return rng.Intn(255) }
+	return []valueGenerator{
+		// "f1 BIGINT",
+		smallInt,
+		// "f2 BIGSERIAL",
+		smallInt,
+		// "f3 BOOLEAN",
+		func() interface{} { return rng.Intn(255) > 128 },
+		// "f4 DATE",
+		func() interface{} { return defaultTableBaseDate.AddDate(0, 0, rng.Intn(365)) },
+		// "f5 FLOAT",
+		func() interface{} { return rng.Float32() },
+		// "f6 REAL",
+		func() interface{} { return rng.Float64() },
+		// "f7 INTEGER",
+		smallInt,
+		// "f8 NUMERIC",
+		smallInt,
+		// "f9 SMALLINT",
+		smallInt,
+		// "f10 SMALLSERIAL",
+		smallInt,
+		// "f11 SERIAL",
+		smallInt,
+	}
+}
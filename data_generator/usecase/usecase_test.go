@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+func TestNewDataGeneratorUsecase(t *testing.T) {
+	dguc, err := NewDataGeneratorUsecase(&domain.TableConfig{
+		Columns: []domain.ColumnConfig{
+			{Name: "u", Distribution: domain.ValueDistribution_Uniform, Min: 10, Max: 20},
+			{Name: "z", Distribution: domain.ValueDistribution_Zipfian, Max: 1000},
+			{Name: "s", Distribution: domain.ValueDistribution_Sequential, Min: 5},
+			{Name: "t", Distribution: domain.ValueDistribution_Text, Length: 4, MaxLength: 8},
+			{Name: "j", Distribution: domain.ValueDistribution_Jsonb, Length: 2},
+			{Name: "id2", Distribution: domain.ValueDistribution_Uuid},
+			{Name: "b", Distribution: domain.ValueDistribution_Bytea, Length: 3},
+		},
+	}, 1)
+	if err != nil {
+		t.Fatalf("NewDataGeneratorUsecase() error = %v", err)
+	}
+
+	if fields := dguc.Fields(); fields[0] != "id BIGSERIAL PRIMARY KEY" || fields[4] != "t TEXT" || fields[5] != "j JSONB" {
+		t.Errorf("fields = %v", fields)
+	}
+	if conditions := dguc.SelectConditions(); conditions != "u>15 AND z>500 AND s>130" {
+		t.Errorf("select conditions = %s", conditions)
+	}
+
+	uuidRegexp := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	values := dguc.Generate(100)
+	if len(values) != 100 {
+		t.Fatalf("generated rows = %d, want 100", len(values))
+	}
+	for i, row := range values {
+		if u := row["u"].(int64); u < 10 || u > 20 {
+			t.Errorf("uniform value = %d, want in [10, 20]", u)
+		}
+		if z := row["z"].(int64); z < 0 || z > 1000 {
+			t.Errorf("zipfian value = %d, want in [0, 1000]", z)
+		}
+		if s := row["s"].(int64); s != int64(5+i) {
+			t.Errorf("sequential value = %d, want %d", s, 5+i)
+		}
+		if text := row["t"].(string); len(text) < 4 || len(text) > 8 {
+			t.Errorf("text length = %d, want in [4, 8]", len(text))
+		}
+		if uuid := row["id2"].(string); !uuidRegexp.MatchString(uuid) {
+			t.Errorf("uuid = %s, want version 4 uuid", uuid)
+		}
+		if b := row["b"].([]byte); len(b) != 3 {
+			t.Errorf("bytea length = %d, want 3", len(b))
+		}
+	}
+}
+
+func TestNewDataGeneratorUsecase_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		column      domain.ColumnConfig
+		expectedErr error
+	}{
+		{name: "no name", column: domain.ColumnConfig{Distribution: domain.ValueDistribution_Uniform}, expectedErr: domain.COLUMN_NAME_IS_REQUIRED},
+		{name: "unknown distribution", column: domain.ColumnConfig{Name: "c", Distribution: "normal"}, expectedErr: domain.UNKNOWN_VALUE_DISTRIBUTION},
+		{name: "zipf exponent", column: domain.ColumnConfig{Name: "c", Distribution: domain.ValueDistribution_Zipfian, ZipfExponent: 0.5}, expectedErr: domain.INVALID_ZIPF_EXPONENT},
+		{name: "range", column: domain.ColumnConfig{Name: "c", Distribution: domain.ValueDistribution_Uniform, Min: 10, Max: 5}, expectedErr: domain.INVALID_VALUES_RANGE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewDataGeneratorUsecase(&domain.TableConfig{Columns: []domain.ColumnConfig{tt.column}}, 1); err != tt.expectedErr {
+				t.Errorf("NewDataGeneratorUsecase() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestDataGeneratorUsecase_Seed(t *testing.T) {
+	cfg := &domain.TableConfig{Columns: []domain.ColumnConfig{
+		{Name: "z", Distribution: domain.ValueDistribution_Zipfian},
+		{Name: "t", Distribution: domain.ValueDistribution_Text, MaxLength: 32},
+		{Name: "j", Distribution: domain.ValueDistribution_Jsonb},
+	}}
+
+	tests := []struct {
+		name      string
+		cfg       *domain.TableConfig
+		seeds     [2]int64
+		identical bool
+	}{
+		{name: "default schema same seed", seeds: [2]int64{7, 7}, identical: true},
+		{name: "default schema other seed", seeds: [2]int64{7, 8}},
+		{name: "custom schema same seed", cfg: cfg, seeds: [2]int64{7, 7}, identical: true},
+		{name: "custom schema other seed", cfg: cfg, seeds: [2]int64{7, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var datasets [2][]map[string]interface{}
+			for i, seed := range tt.seeds {
+				dguc, err := NewDataGeneratorUsecase(tt.cfg, seed)
+				if err != nil {
+					t.Fatalf("NewDataGeneratorUsecase() error = %v", err)
+				}
+				// Batches shouldn't affect the sequence
+				datasets[i] = append(dguc.Generate(10), dguc.Generate(40)...)
+			}
+
+			if identical := reflect.DeepEqual(datasets[0], datasets[1]); identical != tt.identical {
+				t.Errorf("datasets identical = %v, want %v", identical, tt.identical)
+			}
+		})
+	}
+}
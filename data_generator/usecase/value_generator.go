@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+const TEXT_LETTERS = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// valueGenerator returns the next value of the column
+type valueGenerator func() interface{}
+
+func newValueGenerator(rng *rand.Rand, c *domain.ColumnConfig) valueGenerator {
+	switch c.Distribution {
+
+	case domain.ValueDistribution_Zipfian:
+		zipf := rand.NewZipf(rng, c.GetZipfExponent(), 1, uint64(c.GetMax()-c.Min))
+		return func() interface{} { return c.Min + int64(zipf.Uint64()) }
+
+	case domain.ValueDistribution_Sequential:
+		next := c.Min
+		return func() interface{} {
+			v := next
+			next++
+			return v
+		}
+
+	case domain.ValueDistribution_Text:
+		return func() interface{} {
+			b := make([]byte, randomLength(rng, c))
+			for i := range b {
+				b[i] = TEXT_LETTERS[rng.Intn(len(TEXT_LETTERS))]
+			}
+			return string(b)
+		}
+
+	case domain.ValueDistribution_Jsonb:
+		return func() interface{} {
+			object := make(map[string]int64, c.GetLength())
+			for i := 0; i < c.GetLength(); i++ {
+				object["f"+strconv.Itoa(i)] = rng.Int63n(c.GetMax() + 1)
+			}
+			// Error is impossible for the map of integers
+			b, _ := json.Marshal(object)
+			return string(b)
+		}
+
+	case domain.ValueDistribution_Uuid:
+		return func() interface{} {
+			b := make([]byte, 16)
+			rng.Read(b)
+			// Version 4 and RFC 4122 variant
+			b[6] = (b[6] & 0x0f) | 0x40
+			b[8] = (b[8] & 0x3f) | 0x80
+			return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+		}
+
+	case domain.ValueDistribution_Bytea:
+		return func() interface{} {
+			b := make([]byte, randomLength(rng, c))
+			rng.Read(b)
+			return b
+		}
+
+	default:
+		return func() interface{} { return c.Min + rng.Int63n(c.GetMax()-c.Min+1) }
+	}
+}
+
+// randomLength returns length in [Length, MaxLength]
+func randomLength(rng *rand.Rand, c *domain.ColumnConfig) int {
+	if c.MaxLength <= c.GetLength() {
+		return c.GetLength()
+	}
+	return c.GetLength() + rng.Intn(c.MaxLength-c.GetLength()+1)
+}
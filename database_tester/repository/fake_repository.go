@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"io"
+	"sync"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+// FakeDatabaseTesterRepository is an in-memory repository for unit tests.
+// Errors are scriptable by method name, e.g. "Insert". All calls and inserted rows are recorded.
+type FakeDatabaseTesterRepository struct {
+	mu sync.Mutex
+
+	Errors map[string]error
+	// Count of failed pings before the first successful one
+	PingFailures int
+	// Server version number. Postgres 14 if not set
+	ServerVersion int
+
+	Calls        []string
+	InsertedRows map[string]int
+	// Statements of the executed transactions
+	Transactions [][]Statement
+	opened       bool
+	promoted     bool
+}
+
+func NewFakeDatabaseTesterRepository() *FakeDatabaseTesterRepository {
+	r := new(FakeDatabaseTesterRepository)
+	r.Errors = make(map[string]error)
+	r.InsertedRows = make(map[string]int)
+	return r
+}
+
+// Factory returns repository factory, which always returns this repository
+func (r *FakeDatabaseTesterRepository) Factory() DatabaseTesterRepositoryFactory {
+	return func(tc *domain.TestCase, port uint16) (DatabaseTesterRepository, error) {
+		return r, nil
+	}
+}
+
+// CallsCount returns count of calls of the method
+func (r *FakeDatabaseTesterRepository) CallsCount(method string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, call := range r.Calls {
+		if call == method {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *FakeDatabaseTesterRepository) call(method string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Calls = append(r.Calls, method)
+	return r.Errors[method]
+}
+
+func (r *FakeDatabaseTesterRepository) Open() error {
+	if err := r.call("Open"); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.opened = true
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *FakeDatabaseTesterRepository) Ping() error {
+	if err := r.call("Ping"); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.opened {
+		return domain.CONNECTION_WAS_NOT_ESTABLISHED
+	}
+	if r.PingFailures > 0 {
+		r.PingFailures--
+		return domain.CONNECTION_COULDNT_BE_ESTABLISHED
+	}
+	return nil
+}
+
+func (r *FakeDatabaseTesterRepository) CreateDatabase(name string) error {
+	return r.call("CreateDatabase")
+}
+
+func (r *FakeDatabaseTesterRepository) DropDatabase(name string) error {
+	return r.call("DropDatabase")
+}
+
+func (r *FakeDatabaseTesterRepository) SwitchDatabase(name string) error {
+	return r.call("SwitchDatabase")
+}
+
+func (r *FakeDatabaseTesterRepository) CreateTable(name string, fields []string) error {
+	return r.call("CreateTable")
+}
+
+func (r *FakeDatabaseTesterRepository) TruncateTable(name string) error {
+	return r.call("TruncateTable")
+}
+
+func (r *FakeDatabaseTesterRepository) DropTable(name string) error {
+	return r.call("DropTable")
+}
+
+func (r *FakeDatabaseTesterRepository) Insert(tableName string, columns []string, values []map[string]interface{}) error {
+	if err := r.call("Insert"); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.InsertedRows[tableName] += len(values)
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *FakeDatabaseTesterRepository) SelectById(tableName string, id int) error {
+	return r.call("SelectById")
+}
+
+func (r *FakeDatabaseTesterRepository) SelectByConditions(tableName string, conditions string) error {
+	return r.call("SelectByConditions")
+}
+
+func (r *FakeDatabaseTesterRepository) ExecTransaction(statements []Statement) error {
+	if err := r.call("ExecTransaction"); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.Transactions = append(r.Transactions, statements)
+	r.mu.Unlock()
+	return nil
+}
+
+// CopyFrom reads all rows from the source and records them as inserted
+func (r *FakeDatabaseTesterRepository) CopyFrom(tableName string, columns []string, rows RowsSource) error {
+	if err := r.call("CopyFrom"); err != nil {
+		return err
+	}
+
+	count := 0
+	for {
+		batch, err := rows()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		count += len(batch)
+	}
+
+	r.mu.Lock()
+	r.InsertedRows[tableName] += count
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *FakeDatabaseTesterRepository) ExecScript(script string) error {
+	return r.call("ExecScript")
+}
+
+func (r *FakeDatabaseTesterRepository) GetServerVersion() (int, error) {
+	if err := r.call("GetServerVersion"); err != nil {
+		return 0, err
+	}
+	if r.ServerVersion == 0 {
+		return 140000, nil
+	}
+	return r.ServerVersion, nil
+}
+
+func (r *FakeDatabaseTesterRepository) GetCurrentWalPosition() (string, error) {
+	if err := r.call("GetCurrentWalPosition"); err != nil {
+		return "", err
+	}
+	return "0/0", nil
+}
+
+func (r *FakeDatabaseTesterRepository) IsWalPositionReplayed(position string) (bool, error) {
+	if err := r.call("IsWalPositionReplayed"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *FakeDatabaseTesterRepository) IsInRecovery() (bool, error) {
+	if err := r.call("IsInRecovery"); err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.promoted, nil
+}
+
+func (r *FakeDatabaseTesterRepository) Promote() error {
+	if err := r.call("Promote"); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.promoted = true
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *FakeDatabaseTesterRepository) Close() error {
+	if err := r.call("Close"); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.opened = false
+	r.mu.Unlock()
+	return nil
+}
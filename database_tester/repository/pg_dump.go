@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// End of the COPY data block
+	COPY_END_OF_DATA = `\.`
+	// NULL value of the COPY text format
+	COPY_NULL_VALUE = `\N`
+)
+
+// copyFromStdinRegexp matches COPY statement of pg_dump plain format, e.g. COPY public.users (id, name) FROM stdin;
+var copyFromStdinRegexp = regexp.MustCompile(`(?i)^COPY\s+.+\s+FROM\s+stdin\s*;?\s*$`)
+
+// scriptPart is either SQL statements or COPY FROM STDIN statement with its data rows
+type scriptPart struct {
+	sql           string
+	copyStatement string
+	rows          [][]interface{}
+}
+
+// splitScript splits psql script into SQL statements and COPY data blocks.
+// psql meta-commands, e.g. \connect, aren't supported by the server and are skipped.
+func splitScript(script string) []scriptPart {
+	var (
+		parts    []scriptPart
+		sql      strings.Builder
+		copyPart *scriptPart
+	)
+	for _, line := range strings.Split(strings.TrimSuffix(script, "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+
+		if copyPart != nil {
+			if line == COPY_END_OF_DATA {
+				parts = append(parts, *copyPart)
+				copyPart = nil
+			} else {
+				copyPart.rows = append(copyPart.rows, decodeCopyRow(line))
+			}
+			continue
+		}
+
+		switch {
+		case copyFromStdinRegexp.MatchString(strings.TrimSpace(line)):
+			if strings.TrimSpace(sql.String()) != "" {
+				parts = append(parts, scriptPart{sql: sql.String()})
+			}
+			sql.Reset()
+			copyPart = &scriptPart{copyStatement: strings.TrimSuffix(strings.TrimSpace(line), ";")}
+		case strings.HasPrefix(line, `\`):
+			// psql meta-command
+		default:
+			sql.WriteString(line)
+			sql.WriteString("\n")
+		}
+	}
+
+	// COPY block without the end marker is loaded up to the end of the script
+	if copyPart != nil {
+		parts = append(parts, *copyPart)
+	}
+	if strings.TrimSpace(sql.String()) != "" {
+		parts = append(parts, scriptPart{sql: sql.String()})
+	}
+	return parts
+}
+
+// decodeCopyRow splits COPY text format row by tabs and unescapes its values
+func decodeCopyRow(line string) []interface{} {
+	fields := strings.Split(line, "\t")
+	row := make([]interface{}, len(fields))
+	for i, field := range fields {
+		if field == COPY_NULL_VALUE {
+			row[i] = nil
+		} else {
+			row[i] = unescapeCopyValue(field)
+		}
+	}
+	return row
+}
+
+// unescapeCopyValue decodes backslash sequences of COPY text format
+func unescapeCopyValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		switch c := value[i]; c {
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case 'x':
+			// \xh or \xhh
+			end := i + 1
+			for end < len(value) && end < i+3 && isHexDigit(value[end]) {
+				end++
+			}
+			if end == i+1 {
+				sb.WriteByte(c)
+				continue
+			}
+			b, _ := strconv.ParseUint(value[i+1:end], 16, 8)
+			sb.WriteByte(byte(b))
+			i = end - 1
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			// \o, \oo or \ooo
+			end := i + 1
+			for end < len(value) && end < i+3 && value[end] >= '0' && value[end] <= '7' {
+				end++
+			}
+			b, _ := strconv.ParseUint(value[i:end], 8, 8)
+			sb.WriteByte(byte(b))
+			i = end - 1
+		default:
+			// Any other character is taken literally, e.g. \\
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
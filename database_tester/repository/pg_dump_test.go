@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitScript(t *testing.T) {
+	script := "\\restrict abc\n" +
+		"SET client_encoding = 'UTF8';\n" +
+		"CREATE TABLE public.users (id integer, name text, note text);\n" +
+		"\n" +
+		"COPY public.users (id, name, note) FROM stdin;\n" +
+		"1\talice\t\\N\n" +
+		"2\tbob\tline\\nbreak\\ttab\\\\slash\\101\\x42\n" +
+		"\\.\n" +
+		"\n" +
+		"CREATE INDEX users_name ON public.users (name);\n" +
+		"\\unrestrict abc\n"
+
+	expected := []scriptPart{
+		{sql: "SET client_encoding = 'UTF8';\nCREATE TABLE public.users (id integer, name text, note text);\n\n"},
+		{copyStatement: "COPY public.users (id, name, note) FROM stdin", rows: [][]interface{}{
+			{"1", "alice", nil},
+			{"2", "bob", "line\nbreak\ttab\\slashAB"},
+		}},
+		{sql: "\nCREATE INDEX users_name ON public.users (name);\n"},
+	}
+
+	if parts := splitScript(script); !reflect.DeepEqual(parts, expected) {
+		t.Errorf("splitScript() = %#v, want %#v", parts, expected)
+	}
+}
+
+func TestSplitScript_WithoutCopy(t *testing.T) {
+	script := "CREATE TABLE t (id integer);\nINSERT INTO t VALUES (1);\n"
+	if parts := splitScript(script); len(parts) != 1 || parts[0].sql != script {
+		t.Errorf("splitScript() = %#v, want the whole script", parts)
+	}
+}
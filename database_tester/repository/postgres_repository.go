@@ -3,13 +3,16 @@ package repository
 import (
 	"bytes"
 	"context"
+	"database/sql"
+	"io"
 	"strconv"
 	"time"
 
 	"github.com/iakrevetkho/components-tests/cott/domain"
 	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 const PING_TIMEOUT = 5 * time.Second
@@ -219,6 +222,221 @@ func (r *postgresDatabaseTesterRepository) SelectByConditions(tableName string,
 	return nil
 }
 
+func (r *postgresDatabaseTesterRepository) ExecTransaction(statements []Statement) error {
+	if r.db == nil {
+		return domain.CONNECTION_WAS_NOT_ESTABLISHED
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(tx.Rebind(statement.Query), statement.Args...); err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logrus.WithError(rbErr).Warn("couldn't rollback transaction")
+			}
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *postgresDatabaseTesterRepository) CopyFrom(tableName string, columns []string, rows RowsSource) error {
+	if r.db == nil {
+		return domain.CONNECTION_WAS_NOT_ESTABLISHED
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := r.copyFrom(tx, pq.CopyIn(tableName, columns...), rows); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			logrus.WithError(rbErr).Warn("couldn't rollback transaction")
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// copyFrom sends rows with COPY FROM STDIN statement
+func (r *postgresDatabaseTesterRepository) copyFrom(tx *sql.Tx, copyStatement string, rows RowsSource) error {
+	stmt, err := tx.Prepare(copyStatement)
+	if err != nil {
+		return err
+	}
+
+	for {
+		batch, err := rows()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			stmt.Close()
+			return err
+		}
+
+		for _, row := range batch {
+			if _, err := stmt.Exec(row...); err != nil {
+				stmt.Close()
+				return err
+			}
+		}
+	}
+
+	// Flush buffered rows
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return err
+	}
+	return stmt.Close()
+}
+
+// ExecScript executes script with the simple query protocol.
+// Scripts with COPY FROM STDIN data blocks, e.g. pg_dump plain format, are executed in a single transaction.
+func (r *postgresDatabaseTesterRepository) ExecScript(script string) error {
+	if r.db == nil {
+		return domain.CONNECTION_WAS_NOT_ESTABLISHED
+	}
+
+	parts := splitScript(script)
+	hasCopy := false
+	for _, part := range parts {
+		if part.copyStatement != "" {
+			hasCopy = true
+		}
+	}
+	if !hasCopy {
+		for _, part := range parts {
+			if _, err := r.db.Exec(part.sql); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := r.execScriptParts(tx, parts); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			logrus.WithError(rbErr).Warn("couldn't rollback transaction")
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *postgresDatabaseTesterRepository) execScriptParts(tx *sql.Tx, parts []scriptPart) error {
+	for _, part := range parts {
+		if part.copyStatement == "" {
+			if _, err := tx.Exec(part.sql); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rows := part.rows
+		if err := r.copyFrom(tx, part.copyStatement, func() ([][]interface{}, error) {
+			if rows == nil {
+				return nil, io.EOF
+			}
+			batch := rows
+			rows = nil
+			return batch, nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *postgresDatabaseTesterRepository) GetServerVersion() (int, error) {
+	if r.db == nil {
+		return 0, domain.CONNECTION_WAS_NOT_ESTABLISHED
+	}
+
+	var version string
+	if err := r.db.Get(&version, "SHOW server_version_num"); err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(version)
+}
+
+func (r *postgresDatabaseTesterRepository) GetCurrentWalPosition() (string, error) {
+	if r.db == nil {
+		return "", domain.CONNECTION_WAS_NOT_ESTABLISHED
+	}
+
+	var position string
+	if err := r.db.Get(&position, "SELECT pg_current_wal_lsn()::text"); err != nil {
+		return "", err
+	}
+
+	return position, nil
+}
+
+func (r *postgresDatabaseTesterRepository) IsWalPositionReplayed(position string) (bool, error) {
+	if r.db == nil {
+		return false, domain.CONNECTION_WAS_NOT_ESTABLISHED
+	}
+
+	var replayed bool
+	if err := r.db.Get(&replayed, "SELECT COALESCE(pg_wal_lsn_diff(pg_last_wal_replay_lsn(), $1::pg_lsn) >= 0, false)", position); err != nil {
+		return false, err
+	}
+
+	return replayed, nil
+}
+
+func (r *postgresDatabaseTesterRepository) IsInRecovery() (bool, error) {
+	if r.db == nil {
+		return false, domain.CONNECTION_WAS_NOT_ESTABLISHED
+	}
+
+	var inRecovery bool
+	if err := r.db.Get(&inRecovery, "SELECT pg_is_in_recovery()"); err != nil {
+		return false, err
+	}
+
+	return inRecovery, nil
+}
+
+// Promote calls pg_promote() or creates the trigger file of recovery.conf before Postgres 12
+func (r *postgresDatabaseTesterRepository) Promote() error {
+	if r.db == nil {
+		return domain.CONNECTION_WAS_NOT_ESTABLISHED
+	}
+
+	version, err := r.GetServerVersion()
+	if err != nil {
+		return err
+	}
+
+	promoteQuery := "SELECT pg_promote()"
+	if version < domain.POSTGRES_STANDBY_SIGNAL_SERVER_VERSION {
+		// Server writes the file itself, because the client has no access to the container file system
+		promoteQuery = "COPY (SELECT 1) TO '" + domain.POSTGRES_PROMOTE_TRIGGER_FILE + "'"
+	}
+	if _, err := r.db.Exec(promoteQuery); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r *postgresDatabaseTesterRepository) Close() error {
 	if r.db == nil {
 		return domain.CONNECTION_WAS_NOT_ESTABLISHED
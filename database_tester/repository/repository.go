@@ -1,5 +1,20 @@
 package repository
 
+import (
+	"github.com/iakrevetkho/components-tests/cott/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Statement is a single SQL statement with bind args.
+// Query uses '?' placeholders, which are rebound for the particular backend.
+type Statement struct {
+	Query string
+	Args  []interface{}
+}
+
+// RowsSource returns the next batch of rows. Returns io.EOF if there are no more rows
+type RowsSource func() ([][]interface{}, error)
+
 type DatabaseTesterRepository interface {
 	Open() error
 	Ping() error
@@ -12,5 +27,57 @@ type DatabaseTesterRepository interface {
 	Insert(tableName string, columns []string, values []map[string]interface{}) error
 	SelectById(tableName string, id int) error
 	SelectByConditions(tableName string, conditions string) error
+	// ExecTransaction executes statements in a single transaction
+	ExecTransaction(statements []Statement) error
+	// CopyFrom loads rows with the bulk copy protocol
+	CopyFrom(tableName string, columns []string, rows RowsSource) error
+	// ExecScript executes SQL script with multiple statements
+	ExecScript(script string) error
 	Close() error
 }
+
+// ReplicationRepository is implemented by repositories of components with streaming replication
+type ReplicationRepository interface {
+	// GetServerVersion returns server version number, e.g. 140005 for 14.5
+	GetServerVersion() (int, error)
+	// GetCurrentWalPosition returns current write-ahead log position on primary
+	GetCurrentWalPosition() (string, error)
+	// IsWalPositionReplayed checks that replica replayed write-ahead log up to the position
+	IsWalPositionReplayed(position string) (bool, error)
+	IsInRecovery() (bool, error)
+	// Promote promotes replica to primary
+	Promote() error
+}
+
+// DatabaseTesterRepositoryFactory creates repository for the test case component listening on the host port
+type DatabaseTesterRepositoryFactory func(tc *domain.TestCase, port uint16) (DatabaseTesterRepository, error)
+
+// NewDatabaseTesterRepository creates repository for the test case component with credentials from container env vars
+func NewDatabaseTesterRepository(tc *domain.TestCase, port uint16) (DatabaseTesterRepository, error) {
+	switch tc.ComponentType {
+
+	case domain.ComponentType_Postgres:
+		const (
+			POSTGRES_USER_ENV_VAR     = "POSTGRES_USER"
+			POSTGRES_PASSWORD_ENV_VAR = "POSTGRES_PASSWORD"
+		)
+
+		// Get user from env vars
+		user, ok := tc.EnvVars[POSTGRES_USER_ENV_VAR]
+		if !ok {
+			logrus.WithField("envVarName", POSTGRES_USER_ENV_VAR).Error(domain.NO_REQUIRED_ENV_VAR_KEY)
+			return nil, domain.NO_REQUIRED_ENV_VAR_KEY
+		}
+		// Get password from env vars
+		password, ok := tc.EnvVars[POSTGRES_PASSWORD_ENV_VAR]
+		if !ok {
+			logrus.WithField("envVarName", POSTGRES_PASSWORD_ENV_VAR).Error(domain.NO_REQUIRED_ENV_VAR_KEY)
+			return nil, domain.NO_REQUIRED_ENV_VAR_KEY
+		}
+
+		return NewPostgresDatabaseTesterRepository(port, "localhost", user, password), nil
+
+	default:
+		return nil, domain.UNKNOWN_COMPONENT_FOR_TESTING
+	}
+}
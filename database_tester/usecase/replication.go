@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/iakrevetkho/components-tests/cott/database_tester/repository"
+	"github.com/iakrevetkho/components-tests/cott/domain"
+	metrics_collector "github.com/iakrevetkho/components-tests/cott/metrics_collector/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	REPLICATION_TABLE_NAME = "replication_test_table"
+	// Replica clones primary on start, so it needs more time than primary
+	REPLICA_START_UP_TIMEOUT   = 2 * time.Minute
+	REPLICA_CATCH_UP_TIMEOUT   = time.Minute
+	REPLICA_CATCH_UP_POLL_STEP = time.Millisecond
+)
+
+// testReplication measures replication lag of the inserts ladder, replica read latency and replica promotion time
+func (dtuc *databaseTesterUsecase) testReplication(mcuc metrics_collector.MetricsCollectorUsecase, r repository.DatabaseTesterRepository, ts *tableSchema, tc *domain.TestCase) {
+	names := []string{"openReplicaConnection", "replicaStartUp", "createReplicatedTable", "dropReplicatedTable", "promoteReplica"}
+	for _, i := range ts.sizes {
+		names = append(names, replicationStepNames(i)...)
+	}
+	if !tc.StepFilter.MatchAny(names...) {
+		return
+	}
+
+	primary, ok := r.(repository.ReplicationRepository)
+	if !ok {
+		logrus.WithField("componentType", tc.ComponentType).Warn(domain.REPLICATION_IS_NOT_SUPPORTED)
+		return
+	}
+	if version, err := primary.GetServerVersion(); err != nil {
+		logrus.WithError(err).Warn("couldn't get server version")
+		return
+	} else if version < domain.POSTGRES_MIN_REPLICATION_SERVER_VERSION {
+		logrus.WithFields(logrus.Fields{"image": tc.Image, "serverVersion": version}).Warn(domain.REPLICATION_REQUIRES_POSTGRES_10)
+		return
+	}
+
+	replicaR, err := dtuc.repositoryFactory(tc, tc.Replication.GetReplicaHostPort(tc))
+	if err != nil {
+		logrus.WithError(err).Warn("couldn't create replica repository")
+		return
+	}
+	replica, ok := replicaR.(repository.ReplicationRepository)
+	if !ok {
+		logrus.WithField("componentType", tc.ComponentType).Warn(domain.REPLICATION_IS_NOT_SUPPORTED)
+		return
+	}
+
+	step := &domain.TestCaseStep{Name: "openReplicaConnection", StepFunc: func() error { return replicaR.Open() }}
+	if err := mcuc.CollectStepMetrics(step); err != nil {
+		return
+	}
+	defer func() {
+		if err := replicaR.Close(); err != nil {
+			logrus.WithError(err).Debug("couldn't close replica connection")
+		}
+	}()
+
+	step = &domain.TestCaseStep{Name: "replicaStartUp", StepFunc: func() error { return dtuc.awaitDatabase(replicaR, REPLICA_START_UP_TIMEOUT) }}
+	if err := mcuc.CollectStepMetrics(step); err != nil {
+		return
+	}
+
+	if inRecovery, err := replica.IsInRecovery(); err != nil {
+		logrus.WithError(err).Warn("couldn't check replica recovery state")
+		return
+	} else if !inRecovery {
+		logrus.Warn("replica was promoted in the previous round. Use fresh container lifecycle with failover")
+		return
+	}
+
+	if err := r.DropTable(REPLICATION_TABLE_NAME); err != nil {
+		logrus.WithError(err).Debug("couldn't drop replication table")
+	}
+
+	step = &domain.TestCaseStep{Name: "createReplicatedTable", StepFunc: func() error { return r.CreateTable(REPLICATION_TABLE_NAME, ts.data.Fields()) }}
+	if err := mcuc.CollectStepMetrics(step); err != nil {
+		return
+	}
+
+	// Test database and table should be replicated before switching
+	if _, err := dtuc.awaitReplication(primary, replica); err != nil {
+		logrus.WithError(err).Warn("replica didn't catch up")
+		return
+	}
+	if err := replicaR.SwitchDatabase(dtuc.databaseName); err != nil {
+		logrus.WithError(err).Warn("couldn't switch replica database")
+		return
+	}
+
+	for _, i := range ts.sizes {
+		if !tc.StepFilter.MatchAny(replicationStepNames(i)...) {
+			continue
+		}
+		if err := dtuc.testReplicationInsertSelect(mcuc, r, primary, replicaR, replica, ts, i); err != nil {
+			return
+		}
+	}
+
+	step = &domain.TestCaseStep{Name: "dropReplicatedTable", StepFunc: func() error { return r.DropTable(REPLICATION_TABLE_NAME) }}
+	if err := mcuc.CollectStepMetrics(step); err != nil {
+		return
+	}
+
+	if tc.Replication.Failover {
+		step = &domain.TestCaseStep{Name: "promoteReplica", StepFunc: func() error { return dtuc.promote(replica) }}
+		if err := mcuc.CollectStepMetrics(step); err != nil {
+			return
+		}
+	}
+}
+
+func (dtuc *databaseTesterUsecase) testReplicationInsertSelect(mcuc metrics_collector.MetricsCollectorUsecase, r repository.DatabaseTesterRepository, primary repository.ReplicationRepository, replicaR repository.DatabaseTesterRepository, replica repository.ReplicationRepository, ts *tableSchema, dataCount int) error {
+	testPrefix := strconv.FormatInt(int64(dataCount), 10) + "x"
+
+	insertFunc, payloadSize, err := dtuc.prepareTableData(r, REPLICATION_TABLE_NAME, ts, dataCount)
+	if err != nil {
+		logrus.WithError(err).Warn("couldn't prepare table data")
+		return err
+	}
+
+	var lag time.Duration
+	step := &domain.TestCaseStep{Name: testPrefix + "InsertReplicated", OperationsCount: int64(dataCount), PayloadSizeInBytes: payloadSize, StepFunc: func() error {
+		if err := insertFunc(); err != nil {
+			return err
+		}
+
+		var err error
+		lag, err = dtuc.awaitReplication(primary, replica)
+		return err
+	}}
+	if err := mcuc.CollectStepMetrics(step); err != nil {
+		return err
+	}
+	mcuc.AddStepMetric(step, domain.MetricMeta_ReplicationLag, float64(lag.Microseconds()))
+
+	step = &domain.TestCaseStep{Name: "selectById" + testPrefix + "ReplicaTable", StepFunc: func() error { return replicaR.SelectById(REPLICATION_TABLE_NAME, dataCount/2) }}
+	if err := mcuc.CollectStepMetrics(step); err != nil {
+		return err
+	}
+
+	step = &domain.TestCaseStep{Name: "selectByConditions" + testPrefix + "ReplicaTable", StepFunc: func() error {
+		return replicaR.SelectByConditions(REPLICATION_TABLE_NAME, ts.data.SelectConditions())
+	}}
+	if err := mcuc.CollectStepMetrics(step); err != nil {
+		return err
+	}
+
+	step = &domain.TestCaseStep{Name: "truncate" + testPrefix + "ReplicatedTable", StepFunc: func() error { return r.TruncateTable(REPLICATION_TABLE_NAME) }}
+	if err := mcuc.CollectStepMetrics(step); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// replicationStepNames returns names of the replication test steps for the data size
+func replicationStepNames(dataCount int) []string {
+	testPrefix := strconv.FormatInt(int64(dataCount), 10) + "x"
+	return []string{testPrefix + "InsertReplicated", "selectById" + testPrefix + "ReplicaTable", "selectByConditions" + testPrefix + "ReplicaTable", "truncate" + testPrefix + "ReplicatedTable"}
+}
+
+// awaitReplication waits until replica replays current primary write-ahead log position and returns the waiting time
+func (dtuc *databaseTesterUsecase) awaitReplication(primary, replica repository.ReplicationRepository) (time.Duration, error) {
+	position, err := primary.GetCurrentWalPosition()
+	if err != nil {
+		return 0, err
+	}
+
+	startTime := time.Now()
+	for time.Since(startTime) < REPLICA_CATCH_UP_TIMEOUT {
+		replayed, err := replica.IsWalPositionReplayed(position)
+		if err != nil {
+			return 0, err
+		}
+		if replayed {
+			return time.Since(startTime), nil
+		}
+		time.Sleep(REPLICA_CATCH_UP_POLL_STEP)
+	}
+
+	return 0, domain.REPLICA_CATCH_UP_TIMEOUT
+}
+
+// promote promotes replica and waits until it leaves recovery
+func (dtuc *databaseTesterUsecase) promote(replica repository.ReplicationRepository) error {
+	if err := replica.Promote(); err != nil {
+		return err
+	}
+
+	startTime := time.Now()
+	for time.Since(startTime) < REPLICA_CATCH_UP_TIMEOUT {
+		inRecovery, err := replica.IsInRecovery()
+		if err != nil {
+			return err
+		}
+		if !inRecovery {
+			return nil
+		}
+		time.Sleep(REPLICA_CATCH_UP_POLL_STEP)
+	}
+
+	return domain.REPLICA_CATCH_UP_TIMEOUT
+}
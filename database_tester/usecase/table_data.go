@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"io/ioutil"
+	"os"
+
+	data_generator "github.com/iakrevetkho/components-tests/cott/data_generator/usecase"
+	"github.com/iakrevetkho/components-tests/cott/database_tester/repository"
+	"github.com/iakrevetkho/components-tests/cott/domain"
+	metrics_collector "github.com/iakrevetkho/components-tests/cott/metrics_collector/usecase"
+	"github.com/sirupsen/logrus"
+)
+
+// tableSchema describes data size ladder of the test table and generates its rows
+type tableSchema struct {
+	sizes     []int
+	batchSize int
+	data      data_generator.DataGeneratorUsecase
+	// Pre-generated data files config. Nil if rows are generated in memory before insert steps
+	dataset *domain.DatasetConfig
+}
+
+// newTableSchema creates schema from the table config with data generated from the seed
+func (dtuc *databaseTesterUsecase) newTableSchema(cfg *domain.TableConfig, seed int64) (*tableSchema, error) {
+	data, err := data_generator.NewDataGeneratorUsecase(cfg, seed)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateBatchSize(len(data.Columns())); err != nil {
+		return nil, err
+	}
+
+	ts := &tableSchema{batchSize: cfg.GetBatchSize(), data: data}
+	if cfg != nil {
+		ts.dataset = cfg.Dataset
+	}
+
+	for _, size := range cfg.GetSizes() {
+		if dtuc.maxDataCount > 0 && size > dtuc.maxDataCount {
+			logrus.WithFields(logrus.Fields{"size": size, "maxDataCount": dtuc.maxDataCount}).Warn("table data size exceeds the limit and is skipped")
+			continue
+		}
+		ts.sizes = append(ts.sizes, size)
+	}
+
+	return ts, nil
+}
+
+// prepareTableData returns function filling the table with dataCount rows and the payload size.
+// Rows or dataset file are generated here, so only their loading is measured by the step.
+// Payload size is the dataset file size or the formatted size of the generated rows.
+func (dtuc *databaseTesterUsecase) prepareTableData(r repository.DatabaseTesterRepository, tableName string, ts *tableSchema, dataCount int) (func() error, int64, error) {
+	if ts.dataset == nil {
+		batches := ts.generateBatches(dataCount)
+		var payloadSize int64
+		for _, batch := range batches {
+			payloadSize += data_generator.RowsSize(batch)
+		}
+		return func() error { return dtuc.insertTableData(r, tableName, ts.data.Columns(), batches) }, payloadSize, nil
+	}
+
+	filePath, err := ts.data.WriteDataset(ts.dataset, dataCount)
+	if err != nil {
+		return nil, 0, err
+	}
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, 0, err
+	}
+	return func() error { return dtuc.copyDataset(r, tableName, filePath, ts.dataset.GetFormat(), ts.batchSize) }, fileInfo.Size(), nil
+}
+
+// generateBatches generates dataCount rows split by batches of the schema batch size
+func (ts *tableSchema) generateBatches(dataCount int) [][]map[string]interface{} {
+	var batches [][]map[string]interface{}
+	for generated := 0; generated < dataCount; generated += ts.batchSize {
+		count := ts.batchSize
+		if dataCount-generated < count {
+			count = dataCount - generated
+		}
+		batches = append(batches, ts.data.Generate(count))
+	}
+	return batches
+}
+
+// copyDataset loads csv or parquet file into the table with the bulk copy
+func (dtuc *databaseTesterUsecase) copyDataset(r repository.DatabaseTesterRepository, tableName, filePath string, format domain.DatasetFormat, batchSize int) error {
+	dr, err := data_generator.NewDatasetReader(filePath, format)
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+
+	return r.CopyFrom(tableName, dr.Columns(), func() ([][]interface{}, error) { return dr.Read(batchSize) })
+}
+
+// loadFixtures loads fixtures in the declaration order. Loading stops on the first error, because next fixtures could depend on it
+func (dtuc *databaseTesterUsecase) loadFixtures(mcuc metrics_collector.MetricsCollectorUsecase, r repository.DatabaseTesterRepository, fixtures []domain.FixtureConfig) {
+	for i := range fixtures {
+		fixture := fixtures[i]
+
+		step := &domain.TestCaseStep{Name: "loadFixture[" + fixture.GetName() + "]", StepFunc: func() error { return dtuc.loadFixture(r, &fixture) }}
+		if err := mcuc.CollectStepMetrics(step); err != nil {
+			return
+		}
+	}
+}
+
+func (dtuc *databaseTesterUsecase) loadFixture(r repository.DatabaseTesterRepository, fixture *domain.FixtureConfig) error {
+	switch format := fixture.GetFormat(); format {
+
+	case domain.DatasetFormat_Sql:
+		script, err := ioutil.ReadFile(fixture.FilePath)
+		if err != nil {
+			return err
+		}
+		return r.ExecScript(string(script))
+
+	case domain.DatasetFormat_Csv, domain.DatasetFormat_Parquet:
+		if fixture.Table == "" {
+			return domain.FIXTURE_TABLE_IS_REQUIRED
+		}
+		return dtuc.copyDataset(r, fixture.Table, fixture.FilePath, format, domain.FIXTURE_COPY_BATCH_SIZE)
+
+	default:
+		return domain.UNKNOWN_DATASET_FORMAT
+	}
+}
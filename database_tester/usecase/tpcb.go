@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iakrevetkho/components-tests/cott/database_tester/repository"
+	"github.com/iakrevetkho/components-tests/cott/domain"
+	metrics_collector "github.com/iakrevetkho/components-tests/cott/metrics_collector/usecase"
+	"github.com/sirupsen/logrus"
+	"gonum.org/v1/gonum/stat"
+)
+
+const (
+	TPCB_BRANCHES_TABLE = "pgbench_branches"
+	TPCB_TELLERS_TABLE  = "pgbench_tellers"
+	TPCB_ACCOUNTS_TABLE = "pgbench_accounts"
+	TPCB_HISTORY_TABLE  = "pgbench_history"
+
+	TPCB_TELLERS_PER_BRANCH  = 10
+	TPCB_ACCOUNTS_PER_BRANCH = 100000
+	TPCB_INSERT_BATCH_SIZE   = 1000
+)
+
+var tpcbStepNames = []string{"tpcbInit", "tpcbTransactions", "tpcbDropTables"}
+
+// testTpcB runs pgbench-like TPC-B workload.
+// Tables and transaction mix are the same as in the pgbench built-in "tpcb-like" script.
+func (dtuc *databaseTesterUsecase) testTpcB(mcuc metrics_collector.MetricsCollectorUsecase, r repository.DatabaseTesterRepository, cfg *domain.TpcBConfig, seed int64) {
+	scale := int(cfg.GetScale())
+
+	step := &domain.TestCaseStep{Name: "tpcbInit", StepFunc: func() error { return dtuc.initTpcBTables(r, scale) }}
+	if err := mcuc.CollectStepMetrics(step); err != nil {
+		return
+	}
+
+	var (
+		latencies []float64
+		elapsed   time.Duration
+	)
+	transactionsStep := &domain.TestCaseStep{Name: "tpcbTransactions"}
+	transactionsStep.StepFunc = func() error {
+		var err error
+		startTime := time.Now()
+		latencies, err = dtuc.runTpcBClients(r, scale, int(cfg.GetClients()), int(cfg.GetTransactions()), seed)
+		elapsed = time.Since(startTime)
+		// Completed transactions are the step operations
+		transactionsStep.OperationsCount = int64(len(latencies))
+		return err
+	}
+	step = transactionsStep
+	if err := mcuc.CollectStepMetrics(step); err != nil {
+		return
+	}
+
+	sort.Float64s(latencies)
+	mcuc.AddStepMetric(step, domain.MetricMeta_Tps, float64(len(latencies))/elapsed.Seconds())
+	mcuc.AddStepMetric(step, domain.MetricMeta_LatencyP50, stat.Quantile(0.5, stat.Empirical, latencies, nil))
+	mcuc.AddStepMetric(step, domain.MetricMeta_LatencyP95, stat.Quantile(0.95, stat.Empirical, latencies, nil))
+	mcuc.AddStepMetric(step, domain.MetricMeta_LatencyP99, stat.Quantile(0.99, stat.Empirical, latencies, nil))
+
+	step = &domain.TestCaseStep{Name: "tpcbDropTables", StepFunc: func() error { return dtuc.dropTpcBTables(r) }}
+	if err := mcuc.CollectStepMetrics(step); err != nil {
+		return
+	}
+}
+
+func (dtuc *databaseTesterUsecase) initTpcBTables(r repository.DatabaseTesterRepository, scale int) error {
+	if err := dtuc.dropTpcBTables(r); err != nil {
+		return err
+	}
+
+	if err := r.CreateTable(TPCB_BRANCHES_TABLE, []string{"bid INTEGER PRIMARY KEY", "bbalance INTEGER", "filler CHAR(88)"}); err != nil {
+		return err
+	}
+	if err := r.CreateTable(TPCB_TELLERS_TABLE, []string{"tid INTEGER PRIMARY KEY", "bid INTEGER", "tbalance INTEGER", "filler CHAR(84)"}); err != nil {
+		return err
+	}
+	if err := r.CreateTable(TPCB_ACCOUNTS_TABLE, []string{"aid INTEGER PRIMARY KEY", "bid INTEGER", "abalance INTEGER", "filler CHAR(84)"}); err != nil {
+		return err
+	}
+	if err := r.CreateTable(TPCB_HISTORY_TABLE, []string{"tid INTEGER", "bid INTEGER", "aid INTEGER", "delta INTEGER", "mtime TIMESTAMP", "filler CHAR(22)"}); err != nil {
+		return err
+	}
+
+	if err := dtuc.fillTpcBTable(r, TPCB_BRANCHES_TABLE, []string{"bid", "bbalance"}, scale, func(id int) map[string]interface{} {
+		return map[string]interface{}{"bid": id, "bbalance": 0}
+	}); err != nil {
+		return err
+	}
+	if err := dtuc.fillTpcBTable(r, TPCB_TELLERS_TABLE, []string{"tid", "bid", "tbalance"}, scale*TPCB_TELLERS_PER_BRANCH, func(id int) map[string]interface{} {
+		return map[string]interface{}{"tid": id, "bid": (id-1)/TPCB_TELLERS_PER_BRANCH + 1, "tbalance": 0}
+	}); err != nil {
+		return err
+	}
+	if err := dtuc.fillTpcBTable(r, TPCB_ACCOUNTS_TABLE, []string{"aid", "bid", "abalance", "filler"}, scale*TPCB_ACCOUNTS_PER_BRANCH, func(id int) map[string]interface{} {
+		return map[string]interface{}{"aid": id, "bid": (id-1)/TPCB_ACCOUNTS_PER_BRANCH + 1, "abalance": 0, "filler": ""}
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// fillTpcBTable inserts rows with ids from 1 to count by batches
+func (dtuc *databaseTesterUsecase) fillTpcBTable(r repository.DatabaseTesterRepository, tableName string, columns []string, count int, rowFunc func(id int) map[string]interface{}) error {
+	for id := 1; id <= count; {
+		var values []map[string]interface{}
+		for ; id <= count && len(values) < TPCB_INSERT_BATCH_SIZE; id++ {
+			values = append(values, rowFunc(id))
+		}
+		if err := r.Insert(tableName, columns, values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (dtuc *databaseTesterUsecase) dropTpcBTables(r repository.DatabaseTesterRepository) error {
+	for _, tableName := range []string{TPCB_HISTORY_TABLE, TPCB_ACCOUNTS_TABLE, TPCB_TELLERS_TABLE, TPCB_BRANCHES_TABLE} {
+		if err := r.DropTable(tableName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runTpcBClients executes transactions from concurrent clients and returns latency of every transaction in microseconds.
+// Every client gets own random source derived from the seed.
+func (dtuc *databaseTesterUsecase) runTpcBClients(r repository.DatabaseTesterRepository, scale, clients, transactions int, seed int64) ([]float64, error) {
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		latencies = make([]float64, 0, clients*transactions)
+		firstErr  error
+	)
+
+	for c := 0; c < clients; c++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+
+			rnd := rand.New(rand.NewSource(seed))
+			clientLatencies := make([]float64, 0, transactions)
+			for i := 0; i < transactions; i++ {
+				startTime := time.Now()
+				if err := r.ExecTransaction(dtuc.createTpcBTransaction(rnd, scale)); err != nil {
+					logrus.WithError(err).Warn("tpc-b transaction failed")
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				clientLatencies = append(clientLatencies, float64(time.Since(startTime).Microseconds()))
+			}
+
+			mu.Lock()
+			latencies = append(latencies, clientLatencies...)
+			mu.Unlock()
+		}(seed + int64(c))
+	}
+	wg.Wait()
+
+	return latencies, firstErr
+}
+
+func (dtuc *databaseTesterUsecase) createTpcBTransaction(rnd *rand.Rand, scale int) []repository.Statement {
+	aid := rnd.Intn(scale*TPCB_ACCOUNTS_PER_BRANCH) + 1
+	bid := rnd.Intn(scale) + 1
+	tid := rnd.Intn(scale*TPCB_TELLERS_PER_BRANCH) + 1
+	delta := rnd.Intn(10001) - 5000
+
+	return []repository.Statement{
+		{Query: "UPDATE " + TPCB_ACCOUNTS_TABLE + " SET abalance = abalance + ? WHERE aid = ?", Args: []interface{}{delta, aid}},
+		{Query: "SELECT abalance FROM " + TPCB_ACCOUNTS_TABLE + " WHERE aid = ?", Args: []interface{}{aid}},
+		{Query: "UPDATE " + TPCB_TELLERS_TABLE + " SET tbalance = tbalance + ? WHERE tid = ?", Args: []interface{}{delta, tid}},
+		{Query: "UPDATE " + TPCB_BRANCHES_TABLE + " SET bbalance = bbalance + ? WHERE bid = ?", Args: []interface{}{delta, bid}},
+		{Query: "INSERT INTO " + TPCB_HISTORY_TABLE + " (tid, bid, aid, delta, mtime) VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)", Args: []interface{}{tid, bid, aid, delta}},
+	}
+}
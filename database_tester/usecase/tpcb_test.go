@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	container_launcher "github.com/iakrevetkho/components-tests/cott/container_launcher/usecase"
+	"github.com/iakrevetkho/components-tests/cott/database_tester/repository"
+	"github.com/iakrevetkho/components-tests/cott/domain"
+	metrics_collector "github.com/iakrevetkho/components-tests/cott/metrics_collector/usecase"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestDatabaseTesterUsecase_testTpcB(t *testing.T) {
+	transactionErr := errors.New("transaction error")
+	latencyMetrics := []string{domain.MetricMeta_Tps.Name, domain.MetricMeta_LatencyP50.Name, domain.MetricMeta_LatencyP95.Name, domain.MetricMeta_LatencyP99.Name}
+
+	tests := []struct {
+		name    string
+		cfg     domain.TpcBConfig
+		repoErr error
+		// Count of ExecTransaction calls
+		expectedTransactions int
+		expectedSteps        []string
+		absentSteps          []string
+		// Latency and TPS metrics of the transactions step
+		expectedMetrics bool
+	}{
+		{
+			name:                 "success",
+			cfg:                  domain.TpcBConfig{Clients: 2, Transactions: 3},
+			expectedTransactions: 6,
+			expectedSteps:        []string{"tpcbInit", "tpcbTransactions", "tpcbDropTables"},
+			expectedMetrics:      true,
+		},
+		{
+			name:    "transaction error",
+			cfg:     domain.TpcBConfig{Clients: 2, Transactions: 3},
+			repoErr: transactionErr,
+			// Every client stops on the first failed transaction
+			expectedTransactions: 2,
+			expectedSteps:        []string{"tpcbInit", "tpcbTransactions"},
+			absentSteps:          []string{"tpcbDropTables"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluc := container_launcher.NewFakeContainerLauncherUsecase()
+			r := repository.NewFakeDatabaseTesterRepository()
+			if tt.repoErr != nil {
+				r.Errors["ExecTransaction"] = tt.repoErr
+			}
+			dtuc := NewDatabaseTesterUsecase(cluc, r.Factory()).(*databaseTesterUsecase)
+
+			tc := domain.TestCase{ComponentType: domain.ComponentType_Postgres, TpcB: &tt.cfg}
+			tcra := domain.NewTestCaseResultsAccumulator(&tc)
+			mcuc := metrics_collector.NewMetricsCollectorUsecase(tcra, cluc, []domain.RunningContainer{{Id: "fake"}})
+			dtuc.testTpcB(mcuc, r, tc.TpcB, tc.GetDataSeed())
+
+			if actual := r.CallsCount("ExecTransaction"); actual != tt.expectedTransactions {
+				t.Errorf("ExecTransaction calls count = %d, want %d", actual, tt.expectedTransactions)
+			}
+
+			steps := make(map[string]*domain.TestCaseStepResults)
+			for _, tcsr := range tcra.ToTestCaseResults().StepsResults {
+				steps[tcsr.TestCaseStep.Name] = tcsr
+			}
+			for _, name := range tt.expectedSteps {
+				if _, ok := steps[name]; !ok {
+					t.Errorf("step %s not found in results", name)
+				}
+			}
+			for _, name := range tt.absentSteps {
+				if _, ok := steps[name]; ok {
+					t.Errorf("step %s is found in results", name)
+				}
+			}
+
+			metrics := make(map[string]bool)
+			if tcsr, ok := steps["tpcbTransactions"]; ok {
+				for _, m := range tcsr.Metrics {
+					metrics[m.Meta.Name] = true
+				}
+			}
+			for _, name := range latencyMetrics {
+				if metrics[name] != tt.expectedMetrics {
+					t.Errorf("metric %s found = %t, want %t", name, metrics[name], tt.expectedMetrics)
+				}
+			}
+		})
+	}
+}
+
+func TestDatabaseTesterUsecase_createTpcBTransaction(t *testing.T) {
+	cluc := container_launcher.NewFakeContainerLauncherUsecase()
+	r := repository.NewFakeDatabaseTesterRepository()
+	dtuc := NewDatabaseTesterUsecase(cluc, r.Factory()).(*databaseTesterUsecase)
+
+	tc := domain.TestCase{ComponentType: domain.ComponentType_Postgres, TpcB: &domain.TpcBConfig{Clients: 1, Transactions: 1}}
+	tcra := domain.NewTestCaseResultsAccumulator(&tc)
+	mcuc := metrics_collector.NewMetricsCollectorUsecase(tcra, cluc, []domain.RunningContainer{{Id: "fake"}})
+	dtuc.testTpcB(mcuc, r, tc.TpcB, tc.GetDataSeed())
+
+	if len(r.Transactions) != 1 {
+		t.Fatalf("transactions count = %d, want 1", len(r.Transactions))
+	}
+	// Every '?' placeholder has an arg and is rebound to the Postgres positional one
+	for _, statement := range r.Transactions[0] {
+		query := sqlx.Rebind(sqlx.BindType("postgres"), statement.Query)
+		if strings.Contains(query, "?") || !strings.Contains(query, fmt.Sprintf("$%d", len(statement.Args))) || strings.Contains(query, fmt.Sprintf("$%d", len(statement.Args)+1)) {
+			t.Errorf("rebound query = %q, want %d positional params", query, len(statement.Args))
+		}
+	}
+}
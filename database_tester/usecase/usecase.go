@@ -1,11 +1,11 @@
 package usecase
 
 import (
-	"math/rand"
 	"strconv"
 	"time"
 
 	container_launcher "github.com/iakrevetkho/components-tests/cott/container_launcher/usecase"
+	data_generator "github.com/iakrevetkho/components-tests/cott/data_generator/usecase"
 	"github.com/iakrevetkho/components-tests/cott/database_tester/repository"
 	"github.com/iakrevetkho/components-tests/cott/domain"
 	metrics_collector "github.com/iakrevetkho/components-tests/cott/metrics_collector/usecase"
@@ -13,34 +13,45 @@ import (
 )
 
 const (
-	DATABASE_NAME = "cott_db"
+	DATABASE_NAME      = "cott_db"
+	START_UP_TIMEOUT   = 30 * time.Second
+	START_UP_POLL_STEP = 100 * time.Millisecond
 )
 
 type DatabaseTesterUsecase interface {
-	RunCase(tcra *domain.TestCaseResultsAccumulator, containerId string) error
+	RunCase(tcra *domain.TestCaseResultsAccumulator, rt *domain.RunningTopology) error
 }
 
 type databaseTesterUsecase struct {
 	databaseName string
-	cluc         container_launcher.ContainerLauncherUsecase
+	// Max rows count of the table data size ladder. Sizes are limited by tests only, 0 means unlimited
+	maxDataCount      int
+	cluc              container_launcher.ContainerLauncherUsecase
+	repositoryFactory repository.DatabaseTesterRepositoryFactory
 }
 
-func NewDatabaseTesterUsecase(cluc container_launcher.ContainerLauncherUsecase) DatabaseTesterUsecase {
+func NewDatabaseTesterUsecase(cluc container_launcher.ContainerLauncherUsecase, repositoryFactory repository.DatabaseTesterRepositoryFactory) DatabaseTesterUsecase {
 	dtuc := new(databaseTesterUsecase)
 	dtuc.databaseName = DATABASE_NAME
 	dtuc.cluc = cluc
+	dtuc.repositoryFactory = repositoryFactory
 	return dtuc
 }
 
-func (dtuc *databaseTesterUsecase) RunCase(tcra *domain.TestCaseResultsAccumulator, containerId string) error {
+func (dtuc *databaseTesterUsecase) RunCase(tcra *domain.TestCaseResultsAccumulator, rt *domain.RunningTopology) error {
 	logrus.WithField("testCase", *tcra.TestCase).Debug("run test case")
 
-	r, err := dtuc.createDatabaseRepository(tcra.TestCase)
+	r, err := dtuc.repositoryFactory(tcra.TestCase, tcra.TestCase.Port)
 	if err != nil {
 		return err
 	}
 
-	mcuc := metrics_collector.NewMetricsCollectorUsecase(tcra, dtuc.cluc, containerId)
+	ts, err := dtuc.newTableSchema(tcra.TestCase.Table, tcra.TestCase.GetDataSeed())
+	if err != nil {
+		return err
+	}
+
+	mcuc := metrics_collector.NewMetricsCollectorUsecase(tcra, dtuc.cluc, rt.Containers)
 
 	step := &domain.TestCaseStep{Name: "openConnection", StepFunc: func() error { return r.Open() }}
 	if err := mcuc.CollectStepMetrics(step); err != nil {
@@ -48,18 +59,7 @@ func (dtuc *databaseTesterUsecase) RunCase(tcra *domain.TestCaseResultsAccumulat
 	}
 
 	// Await for DB ready
-	step = &domain.TestCaseStep{Name: "startUp", StepFunc: func() error {
-		// await 30 second
-		for i := 0; i < 300; i++ {
-			if err := r.Ping(); err != nil {
-				time.Sleep(100 * time.Millisecond)
-			} else {
-				// Success
-				return nil
-			}
-		}
-		return domain.CONNECTION_WAS_NOT_ESTABLISHED
-	}}
+	step = &domain.TestCaseStep{Name: "startUp", StepFunc: func() error { return dtuc.awaitDatabase(r, START_UP_TIMEOUT) }}
 	if err := mcuc.CollectStepMetrics(step); err != nil {
 		logrus.WithError(err).Debug("couldn't ping database")
 		time.Sleep(time.Second)
@@ -79,7 +79,17 @@ func (dtuc *databaseTesterUsecase) RunCase(tcra *domain.TestCaseResultsAccumulat
 		return nil
 	}
 
-	dtuc.testTable(mcuc, r, containerId)
+	dtuc.loadFixtures(mcuc, r, tcra.TestCase.Fixtures)
+
+	dtuc.testTable(mcuc, r, ts, tcra.TestCase.StepFilter)
+
+	if tcra.TestCase.TpcB != nil && tcra.TestCase.StepFilter.MatchAny(tpcbStepNames...) {
+		dtuc.testTpcB(mcuc, r, tcra.TestCase.TpcB, tcra.TestCase.GetDataSeed())
+	}
+
+	if tcra.TestCase.Replication != nil {
+		dtuc.testReplication(mcuc, r, ts, tcra.TestCase)
+	}
 
 	if err := r.SwitchDatabase(""); err != nil {
 		return err
@@ -98,57 +108,19 @@ func (dtuc *databaseTesterUsecase) RunCase(tcra *domain.TestCaseResultsAccumulat
 	return nil
 }
 
-func (dtuc *databaseTesterUsecase) createDatabaseRepository(tc *domain.TestCase) (repository.DatabaseTesterRepository, error) {
-	switch tc.ComponentType {
+func (dtuc *databaseTesterUsecase) testTable(mcuc metrics_collector.MetricsCollectorUsecase, r repository.DatabaseTesterRepository, ts *tableSchema, filter *domain.StepFilter) {
+	const tableName = "test_table"
 
-	case domain.ComponentType_Postgres:
-		const (
-			POSTGRES_USER_ENV_VAR     = "POSTGRES_USER"
-			POSTGRES_PASSWORD_ENV_VAR = "POSTGRES_PASSWORD"
-		)
-
-		// Get user from env vars
-		user, ok := tc.EnvVars[POSTGRES_USER_ENV_VAR]
-		if !ok {
-			logrus.WithField("envVarName", POSTGRES_USER_ENV_VAR).Error(domain.NO_REQUIRED_ENV_VAR_KEY)
-			return nil, domain.NO_REQUIRED_ENV_VAR_KEY
-		}
-		// Get password from env vars
-		password, ok := tc.EnvVars[POSTGRES_PASSWORD_ENV_VAR]
-		if !ok {
-			logrus.WithField("envVarName", POSTGRES_PASSWORD_ENV_VAR).Error(domain.NO_REQUIRED_ENV_VAR_KEY)
-			return nil, domain.NO_REQUIRED_ENV_VAR_KEY
-		}
-
-		return repository.NewPostgresDatabaseTesterRepository(tc.Port, "localhost", user, password), nil
-
-	default:
-		return nil, domain.UNKNOWN_COMPONENT_FOR_TESTING
+	// Skip table creation if no step of the test is measured
+	names := []string{"createTable", "truncateEmptyTable", "dropTable"}
+	for _, i := range ts.sizes {
+		names = append(names, tableStepNames(i)...)
+	}
+	if !filter.MatchAny(names...) {
+		return
 	}
-}
-
-func (dtuc *databaseTesterUsecase) testTable(mcuc metrics_collector.MetricsCollectorUsecase, r repository.DatabaseTesterRepository, containerId string) {
-	var (
-		tableName           = "test_table"
-		keyValueTableFields = []string{
-			"id BIGSERIAL PRIMARY KEY",
-			"f1 BIGINT",
-			"f2 BIGSERIAL",
-			"f3 BOOLEAN",
-			"f4 DATE",
-			"f5 FLOAT",
-			"f6 REAL",
-			"f7 INTEGER",
-			"f8 NUMERIC",
-			"f9 SMALLINT",
-			"f10 SMALLSERIAL",
-			"f11 SERIAL",
-		}
-		tableColumns     = []string{"f1", "f2", "f3", "f4", "f5", "f6", "f7", "f8", "f9", "f10", "f11"}
-		selectConditions = "f1>1 AND f2>1 AND f3 AND F5>0.5 AND f6>0.5 AND f7>1 AND f8>1 AND f9>1 AND f10>1 AND f11>1"
-	)
 
-	step := &domain.TestCaseStep{Name: "createTable", StepFunc: func() error { return r.CreateTable(tableName, keyValueTableFields) }}
+	step := &domain.TestCaseStep{Name: "createTable", StepFunc: func() error { return r.CreateTable(tableName, ts.data.Fields()) }}
 	if err := mcuc.CollectStepMetrics(step); err != nil {
 		return
 	}
@@ -158,8 +130,12 @@ func (dtuc *databaseTesterUsecase) testTable(mcuc metrics_collector.MetricsColle
 		return
 	}
 
-	for i := 1; i <= 10000000; i *= 10 {
-		if err := dtuc.testTableInsertSelect(mcuc, r, tableName, tableColumns, selectConditions, i); err != nil {
+	for _, i := range ts.sizes {
+		// Data size is skipped if none of its steps is measured
+		if !filter.MatchAny(tableStepNames(i)...) {
+			continue
+		}
+		if err := dtuc.testTableInsertSelect(mcuc, r, tableName, ts, i); err != nil {
 			return
 		}
 	}
@@ -175,24 +151,16 @@ func (dtuc *databaseTesterUsecase) testTable(mcuc metrics_collector.MetricsColle
 	}
 }
 
-func (dtuc *databaseTesterUsecase) testTableInsertSelect(mcuc metrics_collector.MetricsCollectorUsecase, r repository.DatabaseTesterRepository, tableName string, tableColumns []string, selectConditions string, dataCount int) error {
+func (dtuc *databaseTesterUsecase) testTableInsertSelect(mcuc metrics_collector.MetricsCollectorUsecase, r repository.DatabaseTesterRepository, tableName string, ts *tableSchema, dataCount int) error {
 	testPrefix := strconv.FormatInt(int64(dataCount), 10) + "x"
 
-	step := &domain.TestCaseStep{Name: testPrefix + "InsertEmptyTable", StepFunc: func() error {
-		if dataCount > 1000 {
-			// Postgres bulk insert support max 65536 params
-			// Split insert by 1000 rows
-			for i := dataCount / 1000; i > 0; i-- {
-				if err := r.Insert(tableName, tableColumns, dtuc.generateTableData(1000)); err != nil {
-					return err
-				}
-			}
-		} else {
-			return r.Insert(tableName, tableColumns, dtuc.generateTableData(dataCount))
-		}
+	insertFunc, payloadSize, err := dtuc.prepareTableData(r, tableName, ts, dataCount)
+	if err != nil {
+		logrus.WithError(err).Warn("couldn't prepare table data")
+		return err
+	}
 
-		return nil
-	}}
+	step := &domain.TestCaseStep{Name: testPrefix + "InsertEmptyTable", StepFunc: insertFunc, OperationsCount: int64(dataCount), PayloadSizeInBytes: payloadSize}
 	if err := mcuc.CollectStepMetrics(step); err != nil {
 		return err
 	}
@@ -202,7 +170,7 @@ func (dtuc *databaseTesterUsecase) testTableInsertSelect(mcuc metrics_collector.
 		return err
 	}
 
-	step = &domain.TestCaseStep{Name: "selectByConditions" + testPrefix + "Table", StepFunc: func() error { return r.SelectByConditions(tableName, selectConditions) }}
+	step = &domain.TestCaseStep{Name: "selectByConditions" + testPrefix + "Table", StepFunc: func() error { return r.SelectByConditions(tableName, ts.data.SelectConditions()) }}
 	if err := mcuc.CollectStepMetrics(step); err != nil {
 		return err
 	}
@@ -212,7 +180,14 @@ func (dtuc *databaseTesterUsecase) testTableInsertSelect(mcuc metrics_collector.
 		for i := 1000; i >= 1; i /= 10 {
 			insertTestPrefix := strconv.FormatInt(int64(i), 10) + "x"
 
-			step = &domain.TestCaseStep{Name: insertTestPrefix + "Insert" + testPrefix + "Table", StepFunc: func() error { return r.Insert(tableName, tableColumns, dtuc.generateTableData(i)) }}
+			// Rows are generated before the step, so only the insert is measured
+			rows := ts.data.Generate(i)
+			step = &domain.TestCaseStep{
+				Name:               insertTestPrefix + "Insert" + testPrefix + "Table",
+				StepFunc:           func() error { return r.Insert(tableName, ts.data.Columns(), rows) },
+				OperationsCount:    int64(i),
+				PayloadSizeInBytes: data_generator.RowsSize(rows),
+			}
 			if err := mcuc.CollectStepMetrics(step); err != nil {
 				return err
 			}
@@ -227,53 +202,39 @@ func (dtuc *databaseTesterUsecase) testTableInsertSelect(mcuc metrics_collector.
 	return nil
 }
 
-// Method geerates data set for:
-/*
-keyValueTableFields = []string{
-	"f1 BIGINT",
-	"f2 BIGSERIAL",
-	"f3 BOOLEAN",
-	"f4 DATE",
-	"f5 FLOAT",
-	"f6 REAL",
-	"f7 INTEGER",
-	"f8 NUMERIC",
-	"f9 SMALLINT",
-	"f10 SMALLSERIAL",
-	"f11 SERIAL",
+// tableStepNames returns names of the table test steps for the data size
+func tableStepNames(dataCount int) []string {
+	testPrefix := strconv.FormatInt(int64(dataCount), 10) + "x"
+
+	names := []string{testPrefix + "InsertEmptyTable", "selectById" + testPrefix + "Table", "selectByConditions" + testPrefix + "Table"}
+	if dataCount >= 1000 {
+		for i := 1000; i >= 1; i /= 10 {
+			names = append(names, strconv.FormatInt(int64(i), 10)+"x"+"Insert"+testPrefix+"Table")
+		}
+	}
+	return append(names, "truncate"+testPrefix+"Table")
+}
+
+// awaitDatabase pings database until it responds or timeout is reached
+func (dtuc *databaseTesterUsecase) awaitDatabase(r repository.DatabaseTesterRepository, timeout time.Duration) error {
+	startTime := time.Now()
+	for time.Since(startTime) < timeout {
+		if err := r.Ping(); err == nil {
+			// Success
+			return nil
+		}
+		time.Sleep(START_UP_POLL_STEP)
+	}
+	return domain.CONNECTION_WAS_NOT_ESTABLISHED
 }
-*/
-func (dtuc *databaseTesterUsecase) generateTableData(count int) []map[string]interface{} {
-	var values []map[string]interface{}
-
-	for i := 0; i < count; i++ {
-		valuesSet := make(map[string]interface{})
-
-		// "f1 BIGINT",
-		valuesSet["f1"] = rand.Intn(255)
-		// "f2 BIGSERIAL",
-		valuesSet["f2"] = rand.Intn(255)
-		// "f3 BOOLEAN",
-		valuesSet["f3"] = rand.Intn(255) > 128
-		// "f4 DATE",
-		valuesSet["f4"] = time.Now()
-		// "f5 FLOAT",
-		valuesSet["f5"] = rand.Float32()
-		// "f6 REAL",
-		valuesSet["f6"] = rand.Float64()
-		// "f7 INTEGER",
-		valuesSet["f7"] = rand.Intn(255)
-		// "f8 NUMERIC",
-		valuesSet["f8"] = rand.Intn(255)
-		// "f9 SMALLINT",
-		valuesSet["f9"] = rand.Intn(255)
-		// "f10 SMALLSERIAL",
-		valuesSet["f10"] = rand.Intn(255)
-		// "f11 SERIAL",
-		valuesSet["f11"] = rand.Intn(255)
-
-		values = append(values, valuesSet)
+
+// insertTableData inserts pre-generated batches, because Postgres bulk insert supports max 65535 params
+func (dtuc *databaseTesterUsecase) insertTableData(r repository.DatabaseTesterRepository, tableName string, columns []string, batches [][]map[string]interface{}) error {
+	for _, batch := range batches {
+		if err := r.Insert(tableName, columns, batch); err != nil {
+			return err
+		}
 	}
 
-	return values
+	return nil
 }
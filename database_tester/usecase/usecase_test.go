@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	container_launcher "github.com/iakrevetkho/components-tests/cott/container_launcher/usecase"
+	"github.com/iakrevetkho/components-tests/cott/database_tester/repository"
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+func TestDatabaseTesterUsecase_RunCase(t *testing.T) {
+	insertErr := errors.New("insert error")
+	factoryErr := errors.New("factory error")
+
+	tests := []struct {
+		name          string
+		tc            domain.TestCase
+		repoErrors    map[string]error
+		pingFailures  int
+		serverVersion int
+		factoryErr    error
+		expectedErr   error
+		expectedSteps []string
+		absentSteps   []string
+		expectedCalls map[string]int
+	}{
+		{
+			name:          "success",
+			tc:            domain.TestCase{ComponentType: domain.ComponentType_Postgres},
+			expectedSteps: []string{"openConnection", "startUp", "createDatabase", "switchDatabase", "createTable", "1xInsertEmptyTable", "1000xInsertEmptyTable", "1000xInsert1000xTable", "dropDatabase", "closeConnection"},
+			// 1, 10, 100 and 1000 rows inserts plus inserts into full table
+			expectedCalls: map[string]int{"Insert": 8, "SelectById": 4, "TruncateTable": 5},
+		},
+		{
+			name:          "ping retries",
+			tc:            domain.TestCase{ComponentType: domain.ComponentType_Postgres},
+			pingFailures:  2,
+			expectedSteps: []string{"startUp", "closeConnection"},
+			expectedCalls: map[string]int{"Ping": 3},
+		},
+		{
+			name:          "insert error stops table test",
+			tc:            domain.TestCase{ComponentType: domain.ComponentType_Postgres},
+			repoErrors:    map[string]error{"Insert": insertErr},
+			expectedSteps: []string{"1xInsertEmptyTable", "dropDatabase"},
+			expectedCalls: map[string]int{"Insert": 1, "SelectById": 0},
+		},
+		{
+			name:          "step filter",
+			tc:            domain.TestCase{ComponentType: domain.ComponentType_Postgres, TpcB: &domain.TpcBConfig{}, StepFilter: &domain.StepFilter{Include: []string{"*InsertEmptyTable"}, Exclude: []string{"1000x*"}}},
+			expectedSteps: []string{"1xInsertEmptyTable", "100xInsertEmptyTable"},
+			absentSteps:   []string{"openConnection", "createTable", "selectById1xTable", "1000xInsertEmptyTable", "tpcbInit"},
+			// 1000 rows size is skipped
+			expectedCalls: map[string]int{"Insert": 3, "ExecTransaction": 0},
+		},
+		{
+			name: "custom table",
+			tc: domain.TestCase{ComponentType: domain.ComponentType_Postgres, Table: &domain.TableConfig{
+				Sizes:     []int{5, 1000},
+				BatchSize: 300,
+				Columns:   []domain.ColumnConfig{{Name: "c", Distribution: domain.ValueDistribution_Text}},
+			}},
+			expectedSteps: []string{"5xInsertEmptyTable", "selectByConditions5xTable", "1000xInsertEmptyTable", "1xInsert1000xTable"},
+			absentSteps:   []string{"1xInsertEmptyTable"},
+			// 5 rows, 1000 rows by 300 rows batches and inserts into full table
+			expectedCalls: map[string]int{"Insert": 9},
+		},
+		{
+			name:        "invalid table",
+			tc:          domain.TestCase{ComponentType: domain.ComponentType_Postgres, Table: &domain.TableConfig{Columns: []domain.ColumnConfig{{Name: "c"}}}},
+			expectedErr: domain.UNKNOWN_VALUE_DISTRIBUTION,
+		},
+		{
+			name:          "tpc-b",
+			tc:            domain.TestCase{ComponentType: domain.ComponentType_Postgres, TpcB: &domain.TpcBConfig{Clients: 2, Transactions: 5}},
+			expectedSteps: []string{"tpcbInit", "tpcbTransactions", "tpcbDropTables"},
+			expectedCalls: map[string]int{"ExecTransaction": 10},
+		},
+		{
+			name:          "replication with failover",
+			tc:            domain.TestCase{ComponentType: domain.ComponentType_Postgres, Replication: &domain.ReplicationConfig{Failover: true}},
+			expectedSteps: []string{"replicaStartUp", "createReplicatedTable", "1000xInsertReplicated", "selectById1000xReplicaTable", "dropReplicatedTable", "promoteReplica"},
+			expectedCalls: map[string]int{"IsWalPositionReplayed": 5, "Promote": 1},
+		},
+		{
+			name:          "replication on unsupported version",
+			tc:            domain.TestCase{ComponentType: domain.ComponentType_Postgres, Replication: &domain.ReplicationConfig{Failover: true}},
+			serverVersion: 90624,
+			expectedSteps: []string{"1000xInsertEmptyTable", "dropDatabase"},
+			absentSteps:   []string{"openReplicaConnection", "createReplicatedTable", "promoteReplica"},
+			expectedCalls: map[string]int{"Promote": 0},
+		},
+		{
+			name:          "replication catch up error",
+			tc:            domain.TestCase{ComponentType: domain.ComponentType_Postgres, Replication: &domain.ReplicationConfig{}},
+			repoErrors:    map[string]error{"IsWalPositionReplayed": insertErr},
+			expectedSteps: []string{"createReplicatedTable", "dropDatabase"},
+			expectedCalls: map[string]int{"Promote": 0},
+		},
+		{
+			name:        "factory error",
+			tc:          domain.TestCase{ComponentType: domain.ComponentType_Postgres},
+			factoryErr:  factoryErr,
+			expectedErr: factoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluc := container_launcher.NewFakeContainerLauncherUsecase()
+			r := repository.NewFakeDatabaseTesterRepository()
+			r.PingFailures = tt.pingFailures
+			r.ServerVersion = tt.serverVersion
+			for method, err := range tt.repoErrors {
+				r.Errors[method] = err
+			}
+
+			factory := r.Factory()
+			if tt.factoryErr != nil {
+				factory = func(tc *domain.TestCase, port uint16) (repository.DatabaseTesterRepository, error) {
+					return nil, tt.factoryErr
+				}
+			}
+
+			dtuc := NewDatabaseTesterUsecase(cluc, factory).(*databaseTesterUsecase)
+			dtuc.maxDataCount = 1000
+
+			tcra := domain.NewTestCaseResultsAccumulator(&tt.tc)
+			if err := dtuc.RunCase(tcra, &domain.RunningTopology{Containers: []domain.RunningContainer{{Id: "fake"}}}); err != tt.expectedErr {
+				t.Fatalf("RunCase() error = %v, want %v", err, tt.expectedErr)
+			}
+
+			steps := make(map[string]*domain.TestCaseStepResults)
+			for _, tcsr := range tcra.ToTestCaseResults().StepsResults {
+				steps[tcsr.TestCaseStep.Name] = tcsr
+			}
+			for _, name := range tt.expectedSteps {
+				if _, ok := steps[name]; !ok {
+					t.Errorf("step %s not found in results", name)
+				}
+			}
+			for _, name := range tt.absentSteps {
+				if _, ok := steps[name]; ok {
+					t.Errorf("step %s is found in results", name)
+				}
+			}
+			for method, expected := range tt.expectedCalls {
+				if actual := r.CallsCount(method); actual != expected {
+					t.Errorf("%s calls count = %d, want %d", method, actual, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestDatabaseTesterUsecase_RunCaseWithDatasets(t *testing.T) {
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "schema.sql")
+	if err := ioutil.WriteFile(scriptPath, []byte("CREATE TABLE users (id integer, name text);"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	csvPath := filepath.Join(dir, "users.csv")
+	if err := ioutil.WriteFile(csvPath, []byte("id,name\n1,alice\n2,bob\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tc := domain.TestCase{
+		ComponentType: domain.ComponentType_Postgres,
+		Table:         &domain.TableConfig{Sizes: []int{1, 10}, Dataset: &domain.DatasetConfig{Directory: filepath.Join(dir, "datasets")}},
+		Fixtures: []domain.FixtureConfig{
+			{FilePath: scriptPath},
+			{Name: "users", FilePath: csvPath, Table: "users"},
+		},
+	}
+
+	cluc := container_launcher.NewFakeContainerLauncherUsecase()
+	r := repository.NewFakeDatabaseTesterRepository()
+	dtuc := NewDatabaseTesterUsecase(cluc, r.Factory()).(*databaseTesterUsecase)
+
+	tcra := domain.NewTestCaseResultsAccumulator(&tc)
+	if err := dtuc.RunCase(tcra, &domain.RunningTopology{Containers: []domain.RunningContainer{{Id: "fake"}}}); err != nil {
+		t.Fatalf("RunCase() error = %v", err)
+	}
+
+	steps := make(map[string]bool)
+	for _, tcsr := range tcra.ToTestCaseResults().StepsResults {
+		steps[tcsr.TestCaseStep.Name] = true
+	}
+	for _, name := range []string{"loadFixture[schema.sql]", "loadFixture[users]", "1xInsertEmptyTable", "10xInsertEmptyTable"} {
+		if !steps[name] {
+			t.Errorf("step %s not found in results", name)
+		}
+	}
+
+	// Users fixture and both data sizes are copied, inserts are not used
+	for method, expected := range map[string]int{"ExecScript": 1, "CopyFrom": 3, "Insert": 0} {
+		if actual := r.CallsCount(method); actual != expected {
+			t.Errorf("%s calls count = %d, want %d", method, actual, expected)
+		}
+	}
+	if r.InsertedRows["users"] != 2 || r.InsertedRows["test_table"] != 11 {
+		t.Errorf("inserted rows = %v, want 2 users and 11 test_table rows", r.InsertedRows)
+	}
+
+	// Insert steps declare copied rows and their size
+	for _, tcsr := range tcra.ToTestCaseResults().StepsResults {
+		if tcsr.TestCaseStep.Name != "10xInsertEmptyTable" {
+			continue
+		}
+		if tcsr.TestCaseStep.OperationsCount != 10 || tcsr.TestCaseStep.PayloadSizeInBytes == 0 {
+			t.Errorf("step operations = %d and payload = %d, want 10 operations and non-zero payload", tcsr.TestCaseStep.OperationsCount, tcsr.TestCaseStep.PayloadSizeInBytes)
+		}
+		metrics := make(map[string]bool)
+		for _, m := range tcsr.Metrics {
+			metrics[m.Meta.Name] = true
+		}
+		for _, name := range []string{domain.MetricType_OperationsThroughput, domain.MetricType_PayloadThroughput, domain.MetricType_OperationLatency} {
+			if !metrics[name] {
+				t.Errorf("metric %s not found in step results", name)
+			}
+		}
+	}
+}
+
+func TestDatabaseTesterUsecase_RunCaseGeneratedPayload(t *testing.T) {
+	tc := domain.TestCase{ComponentType: domain.ComponentType_Postgres, Table: &domain.TableConfig{Sizes: []int{10}}}
+
+	cluc := container_launcher.NewFakeContainerLauncherUsecase()
+	r := repository.NewFakeDatabaseTesterRepository()
+	dtuc := NewDatabaseTesterUsecase(cluc, r.Factory()).(*databaseTesterUsecase)
+
+	tcra := domain.NewTestCaseResultsAccumulator(&tc)
+	if err := dtuc.RunCase(tcra, &domain.RunningTopology{Containers: []domain.RunningContainer{{Id: "fake"}}}); err != nil {
+		t.Fatalf("RunCase() error = %v", err)
+	}
+
+	// Generated rows size is declared by the insert step, so payload throughput is measured
+	found := false
+	for _, tcsr := range tcra.ToTestCaseResults().StepsResults {
+		if tcsr.TestCaseStep.Name != "10xInsertEmptyTable" {
+			continue
+		}
+		found = true
+		if tcsr.TestCaseStep.PayloadSizeInBytes == 0 {
+			t.Errorf("step payload = 0, want generated rows size")
+		}
+		metrics := make(map[string]bool)
+		for _, m := range tcsr.Metrics {
+			metrics[m.Meta.Name] = true
+		}
+		if !metrics[domain.MetricType_PayloadThroughput] {
+			t.Errorf("metric %s not found in step results", domain.MetricType_PayloadThroughput)
+		}
+	}
+	if !found {
+		t.Errorf("step 10xInsertEmptyTable not found in results")
+	}
+}
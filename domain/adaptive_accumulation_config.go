@@ -0,0 +1,44 @@
+package domain
+
+// AdaptiveAccumulationConfig describes accumulation mode, which runs rounds
+// until confidence interval of every step duration becomes narrow enough
+type AdaptiveAccumulationConfig struct {
+	// Target width of the confidence interval relative to the mean value, e.g. 0.05 for 5%
+	TargetRelativeWidth float64 `json:"target-relative-width"`
+	// Confidence level, e.g. 0.95
+	Confidence       float64 `json:"confidence"`
+	MinAccumulations uint16  `json:"min-accumulations"`
+	MaxAccumulations uint16  `json:"max-accumulations"`
+}
+
+func (c *AdaptiveAccumulationConfig) GetTargetRelativeWidth() float64 {
+	if c.TargetRelativeWidth == 0 {
+		return 0.05
+	} else {
+		return c.TargetRelativeWidth
+	}
+}
+
+func (c *AdaptiveAccumulationConfig) GetConfidence() float64 {
+	if c.Confidence == 0 {
+		return 0.95
+	} else {
+		return c.Confidence
+	}
+}
+
+func (c *AdaptiveAccumulationConfig) GetMinAccumulationsCount() uint16 {
+	if c.MinAccumulations == 0 {
+		return 3
+	} else {
+		return c.MinAccumulations
+	}
+}
+
+func (c *AdaptiveAccumulationConfig) GetMaxAccumulationsCount() uint16 {
+	if c.MaxAccumulations == 0 {
+		return 64
+	} else {
+		return c.MaxAccumulations
+	}
+}
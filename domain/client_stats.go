@@ -0,0 +1,18 @@
+package domain
+
+// ClientStats is a snapshot of the cott process resources usage.
+// It separates driver and data preparation cost from the server cost measured by ContainerStats.
+type ClientStats struct {
+	// CPU time consumed by the process in user mode in nanoseconds
+	UserCpuTime uint64 `json:"user-cpu-time"`
+	// CPU time consumed by the process in kernel mode in nanoseconds
+	SystemCpuTime uint64 `json:"system-cpu-time"`
+	// Cumulative bytes allocated on the heap
+	AllocatedBytes uint64 `json:"allocated-bytes"`
+	// Cumulative count of the heap allocations
+	Allocations uint64 `json:"allocations"`
+	// Estimated total time of the stop-the-world GC pauses in nanoseconds
+	GcPauseTime uint64 `json:"gc-pause-time"`
+	// Count of the completed GC cycles
+	GcCycles uint64 `json:"gc-cycles"`
+}
@@ -1,15 +1,31 @@
 package domain
 
 import (
+	"path/filepath"
+
 	"github.com/sirupsen/logrus"
 )
 
 type Config struct {
 	Log       LogConfig
 	Report    ReportConfig
+	Registry  RegistryConfig
+	Engine    ContainerEngineConfig
+	Filter    FilterConfig
 	TestCases []TestCase
 }
 
+// ResolveHostPaths makes relative host paths of the test cases absolute. Paths are relative to the config file directory
+func (c *Config) ResolveHostPaths(configFilePath string) error {
+	baseDir := filepath.Dir(configFilePath)
+	for i := range c.TestCases {
+		if err := c.TestCases[i].ResolveHostPaths(baseDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type LogConfig struct {
 	Level            logrus.Level `default:"info" env:"LOG_LEVEL"`
 	FilePath         string       `default:"/var/log/cott/cott.log" env:"LOG_FILE_PATH"`
@@ -21,4 +37,59 @@ type LogConfig struct {
 
 type ReportConfig struct {
 	FilePath string `default:"report.json" env:"REPORT_FILE_PATH"`
+	// Metric compared in the summary table
+	TableMetric string `default:"duration" env:"REPORT_TABLE_METRIC"`
+	// Format of the summary table printed to stdout: text, markdown or none
+	TableFormat ReportTableFormat `default:"text" env:"REPORT_TABLE_FORMAT"`
+	// Markdown summary table is appended to the file if set, e.g. $GITHUB_STEP_SUMMARY
+	MarkdownFilePath string `env:"REPORT_MARKDOWN_FILE_PATH"`
+}
+
+type ReportTableFormat string
+
+const (
+	ReportTableFormat_None     = "none"
+	ReportTableFormat_Text     = "text"
+	ReportTableFormat_Markdown = "markdown"
+)
+
+// Validate checks summary table settings before the run, so misconfiguration isn't found after all test cases
+func (c *ReportConfig) Validate() error {
+	switch c.TableFormat {
+	case ReportTableFormat_None, ReportTableFormat_Text, ReportTableFormat_Markdown:
+	default:
+		return UNKNOWN_REPORT_TABLE_FORMAT
+	}
+
+	if FindMetricMeta(c.TableMetric) == nil {
+		return UNKNOWN_METRIC
+	}
+	return nil
+}
+
+// RegistryConfig contains credentials for private registries.
+// Credentials from env vars have priority over Docker config file for the images of the server.
+type RegistryConfig struct {
+	// Registry host of the credentials, e.g. registry.example.com:5000 or docker.io. Required if username is set
+	Server   string `env:"REGISTRY_SERVER"`
+	Username string `env:"REGISTRY_USERNAME"`
+	Password string `env:"REGISTRY_PASSWORD" json:"-"`
+	// Path to Docker config file. $DOCKER_CONFIG/config.json or ~/.docker/config.json if empty
+	DockerConfigFilePath string `env:"REGISTRY_DOCKER_CONFIG_FILE_PATH"`
+}
+
+type ContainerEngine string
+
+const (
+	ContainerEngine_Docker  = "docker"
+	ContainerEngine_Podman  = "podman"
+	ContainerEngine_Nerdctl = "nerdctl"
+)
+
+type ContainerEngineConfig struct {
+	Engine ContainerEngine `default:"docker" env:"CONTAINER_ENGINE"`
+	// Engine API socket, e.g. unix:///run/user/1000/podman/podman.sock. Default engine socket is used if empty
+	Host string `env:"CONTAINER_ENGINE_HOST"`
+	// Path to nerdctl binary for containerd engine
+	NerdctlPath string `default:"nerdctl" env:"CONTAINER_ENGINE_NERDCTL_PATH"`
 }
@@ -0,0 +1,25 @@
+package domain
+
+import "testing"
+
+func TestReportConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         ReportConfig
+		expectedErr error
+	}{
+		{name: "text", cfg: ReportConfig{TableFormat: ReportTableFormat_Text, TableMetric: MetricType_Duration}},
+		{name: "markdown", cfg: ReportConfig{TableFormat: ReportTableFormat_Markdown, TableMetric: MetricType_OperationsThroughput}},
+		{name: "none", cfg: ReportConfig{TableFormat: ReportTableFormat_None, TableMetric: MetricType_Tps}},
+		{name: "unknown format", cfg: ReportConfig{TableFormat: "html", TableMetric: MetricType_Duration}, expectedErr: UNKNOWN_REPORT_TABLE_FORMAT},
+		{name: "unknown metric", cfg: ReportConfig{TableFormat: ReportTableFormat_Text, TableMetric: "durration"}, expectedErr: UNKNOWN_METRIC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); err != tt.expectedErr {
+				t.Errorf("Validate() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
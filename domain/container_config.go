@@ -0,0 +1,37 @@
+package domain
+
+// ContainerConfig describes container to launch
+type ContainerConfig struct {
+	Image   string
+	EnvVars map[string]string
+	Port    uint16
+	// Port on the host to publish container port. Port isn't published if 0
+	HostPort uint16
+	// Command overriding the image CMD. Image CMD is used if empty
+	Cmd       []string
+	Mounts    []Mount
+	Resources ResourceLimits
+	// Network to connect container to. Engine default network is used if empty
+	Network string
+	// Name of the container in the network
+	Alias string
+	// Container shares host network namespace in host mode, so Network and HostPort are ignored. bridge if empty
+	NetworkMode NetworkMode
+}
+
+// ScopeVolumes suffixes names of the named volumes, so every launch gets new empty volumes.
+// Returns names of the scoped volumes to remove them with the containers.
+func (cfg *ContainerConfig) ScopeVolumes(suffix string) []string {
+	var volumes []string
+	// Copy to prevent modification of the test case mounts
+	mounts := make([]Mount, len(cfg.Mounts))
+	for i, m := range cfg.Mounts {
+		if m.Type == MountType_Volume && m.Source != "" {
+			m.Source += "-" + suffix
+			volumes = append(volumes, m.Source)
+		}
+		mounts[i] = m
+	}
+	cfg.Mounts = mounts
+	return volumes
+}
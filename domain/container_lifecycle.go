@@ -0,0 +1,13 @@
+package domain
+
+// ContainerLifecycle defines how containers are reused between accumulation rounds
+type ContainerLifecycle string
+
+const (
+	// One container for all rounds. The first round without warmup is cold start and reported separately, others are warm cache
+	ContainerLifecycle_Shared = "shared"
+	// New container for every round. Every round is cold start
+	ContainerLifecycle_FreshPerRound = "fresh-per-round"
+	// New container for every round. Scenario is run twice: cold start and then warm cache
+	ContainerLifecycle_FreshWithWarmup = "fresh-with-warmup"
+)
@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	LOOPBACK_INTERFACE = "lo"
+
+	BlkioOp_Read  = "read"
+	BlkioOp_Write = "write"
+)
+
+// ContainerStats is an engine independent snapshot of container resources usage
+type ContainerStats struct {
+	Read        time.Time                        `json:"read"`
+	CpuStats    ContainerCpuStats                `json:"cpu-stats"`
+	MemoryStats ContainerMemoryStats             `json:"memory-stats"`
+	BlkioStats  ContainerBlkioStats              `json:"blkio-stats"`
+	Networks    map[string]ContainerNetworkStats `json:"networks"`
+}
+
+type ContainerCpuStats struct {
+	// Total CPU time consumed in nanoseconds
+	TotalUsage uint64 `json:"total-usage"`
+	// CPU time consumed in user mode in nanoseconds
+	UserUsage uint64 `json:"user-usage"`
+	// CPU time consumed in kernel mode in nanoseconds
+	SystemUsage uint64 `json:"system-usage"`
+	// CPUs available for the container on the host
+	OnlineCpus uint32 `json:"online-cpus"`
+	// Cumulative count of CFS periods, when the container was throttled by the CPU limit
+	ThrottledPeriods uint64 `json:"throttled-periods"`
+	// Cumulative time of the container throttling in nanoseconds
+	ThrottledTime uint64 `json:"throttled-time"`
+}
+
+type ContainerMemoryStats struct {
+	// Current usage including page cache
+	Usage uint64 `json:"usage"`
+	// Max usage since the container start. Zero if the engine doesn't report it
+	MaxUsage uint64 `json:"max-usage"`
+	// Memory limit. Zero if unlimited
+	Limit uint64 `json:"limit"`
+	// Anonymous memory
+	Rss uint64 `json:"rss"`
+	// Page cache
+	Cache uint64 `json:"cache"`
+	// Page cache, which could be reclaimed first
+	InactiveFile uint64 `json:"inactive-file"`
+	// Cumulative page faults count
+	PageFaults uint64 `json:"page-faults"`
+	// Cumulative major page faults count
+	MajorPageFaults uint64 `json:"major-page-faults"`
+}
+
+// WorkingSet returns usage without inactive page cache, like kubelet does
+func (s *ContainerMemoryStats) WorkingSet() uint64 {
+	if s.InactiveFile > s.Usage {
+		return 0
+	} else {
+		return s.Usage - s.InactiveFile
+	}
+}
+
+type ContainerBlkioStats struct {
+	// Bytes transferred by device and operation
+	IoServiceBytesRecursive []ContainerBlkioStatEntry `json:"io-service-bytes-recursive"`
+	// IO operations count by device and operation. Docker doesn't fill it on cgroup v2
+	IoServicedRecursive []ContainerBlkioStatEntry `json:"io-serviced-recursive"`
+}
+
+type ContainerBlkioStatEntry struct {
+	Major uint64 `json:"major"`
+	Minor uint64 `json:"minor"`
+	// Lower case operation, e.g. read, write, sync or total
+	Op    string `json:"op"`
+	Value uint64 `json:"value"`
+}
+
+// ContainerDeviceIoStats is IO usage of the block device
+type ContainerDeviceIoStats struct {
+	ReadBytes       uint64 `json:"read-bytes"`
+	WriteBytes      uint64 `json:"write-bytes"`
+	ReadOperations  uint64 `json:"read-operations"`
+	WriteOperations uint64 `json:"write-operations"`
+}
+
+// HasOperations checks whether IO operations counts are reported
+func (s *ContainerBlkioStats) HasOperations() bool {
+	return len(s.IoServicedRecursive) > 0
+}
+
+// Devices returns IO usage by "major:minor" device number
+func (s *ContainerBlkioStats) Devices() map[string]ContainerDeviceIoStats {
+	devices := make(map[string]ContainerDeviceIoStats)
+	for _, entry := range s.IoServiceBytesRecursive {
+		device := devices[entry.Device()]
+		switch strings.ToLower(entry.Op) {
+		case BlkioOp_Read:
+			device.ReadBytes += entry.Value
+		case BlkioOp_Write:
+			device.WriteBytes += entry.Value
+		default:
+			// Sync, async, discard and total entries duplicate read and write ones
+			continue
+		}
+		devices[entry.Device()] = device
+	}
+	for _, entry := range s.IoServicedRecursive {
+		device := devices[entry.Device()]
+		switch strings.ToLower(entry.Op) {
+		case BlkioOp_Read:
+			device.ReadOperations += entry.Value
+		case BlkioOp_Write:
+			device.WriteOperations += entry.Value
+		default:
+			continue
+		}
+		devices[entry.Device()] = device
+	}
+	return devices
+}
+
+// Total returns IO usage summed across all devices
+func (s *ContainerBlkioStats) Total() ContainerDeviceIoStats {
+	var total ContainerDeviceIoStats
+	for _, device := range s.Devices() {
+		total.ReadBytes += device.ReadBytes
+		total.WriteBytes += device.WriteBytes
+		total.ReadOperations += device.ReadOperations
+		total.WriteOperations += device.WriteOperations
+	}
+	return total
+}
+
+// Device returns "major:minor" device number
+func (e *ContainerBlkioStatEntry) Device() string {
+	return strconv.FormatUint(e.Major, 10) + ":" + strconv.FormatUint(e.Minor, 10)
+}
+
+type ContainerNetworkStats struct {
+	RxBytes   uint64 `json:"rx-bytes"`
+	TxBytes   uint64 `json:"tx-bytes"`
+	RxPackets uint64 `json:"rx-packets"`
+	TxPackets uint64 `json:"tx-packets"`
+	RxDropped uint64 `json:"rx-dropped"`
+	TxDropped uint64 `json:"tx-dropped"`
+	RxErrors  uint64 `json:"rx-errors"`
+	TxErrors  uint64 `json:"tx-errors"`
+}
+
+// NetworksTotal returns stats summed across all network interfaces.
+// Loopback traffic is internal for the container network namespace, but it is the client traffic with host networking.
+func (s *ContainerStats) NetworksTotal(includeLoopback bool) ContainerNetworkStats {
+	var total ContainerNetworkStats
+	for name, network := range s.Networks {
+		if name == LOOPBACK_INTERFACE && !includeLoopback {
+			continue
+		}
+		total.RxBytes += network.RxBytes
+		total.TxBytes += network.TxBytes
+		total.RxPackets += network.RxPackets
+		total.TxPackets += network.TxPackets
+		total.RxDropped += network.RxDropped
+		total.TxDropped += network.TxDropped
+		total.RxErrors += network.RxErrors
+		total.TxErrors += network.TxErrors
+	}
+	return total
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type DatasetFormat string
+
+const (
+	DatasetFormat_Csv     = "csv"
+	DatasetFormat_Parquet = "parquet"
+	// Plain SQL script. Fixtures only
+	DatasetFormat_Sql = "sql"
+
+	// Rows count sent to the database at once while loading fixture
+	FIXTURE_COPY_BATCH_SIZE = 1000
+)
+
+// DatasetConfig enables generation of the table data into files before the measured steps.
+// Insert steps load files with the bulk copy, so data generation isn't measured.
+type DatasetConfig struct {
+	// csv or parquet. csv if empty
+	Format DatasetFormat `json:"format"`
+	// Directory of the generated files. Files are reused by test cases with the same table config and seed.
+	// $TMPDIR/cott-datasets if empty
+	Directory string `json:"directory,omitempty"`
+}
+
+// FixtureConfig describes data file loaded into the test database before the table test
+type FixtureConfig struct {
+	// Name of the fixture in the step name. File name if empty
+	Name string `json:"name,omitempty"`
+	// Path to csv file with the header row, parquet file or sql script. Format is detected by extension.
+	// Sql script could be pg_dump plain format output with COPY FROM stdin data blocks
+	FilePath string `json:"file-path"`
+	// Table to load csv or parquet data into. Table should be created by the previous sql fixture
+	Table string `json:"table,omitempty"`
+}
+
+func (c *DatasetConfig) GetFormat() DatasetFormat {
+	if c.Format == "" {
+		return DatasetFormat_Csv
+	} else {
+		return c.Format
+	}
+}
+
+func (c *DatasetConfig) GetDirectory() string {
+	if c.Directory == "" {
+		return filepath.Join(os.TempDir(), "cott-datasets")
+	} else {
+		return c.Directory
+	}
+}
+
+func (c *FixtureConfig) GetName() string {
+	if c.Name == "" {
+		return filepath.Base(c.FilePath)
+	} else {
+		return c.Name
+	}
+}
+
+// GetFormat returns fixture format by the file extension
+func (c *FixtureConfig) GetFormat() DatasetFormat {
+	return DatasetFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(c.FilePath)), "."))
+}
@@ -9,4 +9,35 @@ var (
 	UNKNOWN_COMPONENT_FOR_TESTING        = errors.New("unknown component for testing")
 	NO_REQUIRED_ENV_VAR_KEY              = errors.New("couldn't find required env var for container")
 	COULDNT_CLOSE_CONTAINER_STATS_READER = errors.New("couldn't close containers stats reader")
+	UNKNOWN_CONTAINER_LIFECYCLE          = errors.New("unknown container lifecycle")
+	UNKNOWN_PULL_POLICY                  = errors.New("unknown image pull policy")
+	UNKNOWN_CONTAINER_ENGINE             = errors.New("unknown container engine")
+	UNSUPPORTED_CGROUP_VERSION           = errors.New("only cgroup v2 is supported")
+	DUPLICATED_CONTAINER_ALIAS           = errors.New("duplicated container alias in topology")
+	UNKNOWN_CONTAINER_DEPENDENCY         = errors.New("unknown container dependency in topology")
+	CYCLIC_CONTAINER_DEPENDENCY          = errors.New("cyclic container dependency in topology")
+	REPLICATION_IS_NOT_SUPPORTED         = errors.New("replication isn't supported by the repository")
+	REPLICATION_REQUIRES_POSTGRES_10     = errors.New("streaming replication requires Postgres 10 or newer")
+	REPLICA_CATCH_UP_TIMEOUT             = errors.New("replica didn't catch up with primary in time")
+	UNKNOWN_MOUNT_TYPE                   = errors.New("unknown mount type")
+	MOUNT_SOURCE_IS_REQUIRED             = errors.New("mount source is required for bind mount")
+	MOUNT_SOURCE_MUST_BE_ABSOLUTE        = errors.New("bind mount source must be absolute path")
+	MOUNT_TARGET_IS_REQUIRED             = errors.New("mount target is required for the component")
+	UNSUPPORTED_VOLUME_DRIVER            = errors.New("volume driver isn't supported by the container engine")
+	COLUMN_NAME_IS_REQUIRED              = errors.New("table column name is required")
+	UNKNOWN_VALUE_DISTRIBUTION           = errors.New("unknown column values distribution")
+	INVALID_ZIPF_EXPONENT                = errors.New("zipf exponent must be greater than 1")
+	INVALID_BATCH_SIZE                   = errors.New("table batch size must be positive")
+	BATCH_EXCEEDS_BIND_PARAMS_LIMIT      = errors.New("table batch size multiplied by columns count exceeds bind params limit")
+	INVALID_VALUES_RANGE                 = errors.New("column values max is less than min")
+	UNKNOWN_DATASET_FORMAT               = errors.New("unknown dataset format")
+	FIXTURE_TABLE_IS_REQUIRED            = errors.New("table is required for csv and parquet fixtures")
+	UNKNOWN_NETWORK_MODE                 = errors.New("unknown network mode")
+	HOST_NETWORK_WITH_TOPOLOGY           = errors.New("host network mode doesn't support topology and replication")
+	OOM_KILL_DURING_STEP                 = errors.New("process was killed by the OOM killer during the step")
+	IMAGE_NOT_PRESENT_LOCALLY            = errors.New("image isn't present locally and pull policy is never")
+	REGISTRY_SERVER_IS_REQUIRED          = errors.New("registry server is required for registry credentials")
+	UNKNOWN_METRIC                       = errors.New("unknown metric")
+	UNKNOWN_REPORT_TABLE_FORMAT          = errors.New("unknown report table format")
+	INVALID_FILTER_PATTERN               = errors.New("filter pattern ends with escape character")
 )
@@ -0,0 +1,152 @@
+package domain
+
+// FilterConfig selects test cases and steps to run. Names are matched by glob patterns, e.g. "*InsertEmptyTable".
+// "*" matches any characters including "/", "?" matches one character and "\" escapes the next one.
+// There are no character classes, so "[" in names like "postgres[image=postgres:14]" is matched literally.
+// Everything is included if include lists are empty. Excludes have priority over includes.
+type FilterConfig struct {
+	IncludeCases []string `env:"FILTER_INCLUDE_CASES"`
+	ExcludeCases []string `env:"FILTER_EXCLUDE_CASES"`
+	IncludeTags  []string `env:"FILTER_INCLUDE_TAGS"`
+	ExcludeTags  []string `env:"FILTER_EXCLUDE_TAGS"`
+	IncludeSteps []string `env:"FILTER_INCLUDE_STEPS"`
+	ExcludeSteps []string `env:"FILTER_EXCLUDE_STEPS"`
+}
+
+// Validate checks filter patterns before the run
+func (c *FilterConfig) Validate() error {
+	for _, patterns := range [][]string{c.IncludeCases, c.ExcludeCases, c.IncludeSteps, c.ExcludeSteps} {
+		for _, pattern := range patterns {
+			if err := validatePattern(pattern); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// StepFilter selects test case steps by names glob patterns. Nil filter matches all steps
+type StepFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Match checks whether the step should be measured
+func (f *StepFilter) Match(name string) bool {
+	if f == nil {
+		return true
+	}
+	return (len(f.Include) == 0 || matchAnyPattern(f.Include, name)) && !matchAnyPattern(f.Exclude, name)
+}
+
+// MatchAny checks whether any of the steps should be measured
+func (f *StepFilter) MatchAny(names ...string) bool {
+	for _, name := range names {
+		if f.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterTestCases returns test cases selected by the filter with step filter applied
+func FilterTestCases(tcs []TestCase, filter FilterConfig) []TestCase {
+	var stepFilter *StepFilter
+	if len(filter.IncludeSteps) > 0 || len(filter.ExcludeSteps) > 0 {
+		stepFilter = &StepFilter{Include: filter.IncludeSteps, Exclude: filter.ExcludeSteps}
+	}
+
+	var filtered []TestCase
+	for _, tc := range tcs {
+		name := tc.GetName()
+		if len(filter.IncludeCases) > 0 && !matchAnyPattern(filter.IncludeCases, name) {
+			continue
+		}
+		if matchAnyPattern(filter.ExcludeCases, name) {
+			continue
+		}
+		if len(filter.IncludeTags) > 0 && !tc.hasAnyTag(filter.IncludeTags) {
+			continue
+		}
+		if tc.hasAnyTag(filter.ExcludeTags) {
+			continue
+		}
+
+		tc.StepFilter = stepFilter
+		filtered = append(filtered, tc)
+	}
+	return filtered
+}
+
+func (tc *TestCase) hasAnyTag(tags []string) bool {
+	for _, tag := range tc.Tags {
+		for _, t := range tags {
+			if tag == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchAnyPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePattern checks that the pattern doesn't end with escape character
+func validatePattern(pattern string) error {
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '\\' {
+			if i+1 == len(pattern) {
+				return INVALID_FILTER_PATTERN
+			}
+			i++
+		}
+	}
+	return nil
+}
+
+// matchPattern matches the whole name by the glob pattern. Trailing escape character is matched literally.
+func matchPattern(pattern, name string) bool {
+	// Position of the last star and name position it matched up to, to backtrack on mismatch
+	starIdx, starNameIdx := -1, 0
+	p, n := 0, 0
+	for n < len(name) {
+		if p < len(pattern) {
+			switch c := pattern[p]; {
+			case c == '*':
+				starIdx, starNameIdx = p, n
+				p++
+				continue
+			case c == '?':
+				p++
+				n++
+				continue
+			case c == '\\' && p+1 < len(pattern):
+				if pattern[p+1] == name[n] {
+					p += 2
+					n++
+					continue
+				}
+			case c == name[n]:
+				p++
+				n++
+				continue
+			}
+		}
+		if starIdx < 0 {
+			return false
+		}
+		starNameIdx++
+		p, n = starIdx+1, starNameIdx
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,90 @@
+package domain
+
+import "testing"
+
+func TestFilterTestCases(t *testing.T) {
+	tcs := []TestCase{
+		{Name: "postgres-13", Tags: []string{"postgres", "slow"}},
+		{Name: "postgres-14", Tags: []string{"postgres"}},
+		{Image: "postgres:14"},
+	}
+
+	tests := []struct {
+		name          string
+		filter        FilterConfig
+		expectedNames []string
+	}{
+		{name: "no filter", expectedNames: []string{"postgres-13", "postgres-14", "postgres:14"}},
+		{name: "include cases", filter: FilterConfig{IncludeCases: []string{"postgres-*"}}, expectedNames: []string{"postgres-13", "postgres-14"}},
+		{name: "exclude cases", filter: FilterConfig{ExcludeCases: []string{"*13"}}, expectedNames: []string{"postgres-14", "postgres:14"}},
+		{name: "include tags", filter: FilterConfig{IncludeTags: []string{"postgres"}}, expectedNames: []string{"postgres-13", "postgres-14"}},
+		{name: "exclude tags", filter: FilterConfig{IncludeTags: []string{"postgres"}, ExcludeTags: []string{"slow"}}, expectedNames: []string{"postgres-14"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := FilterTestCases(tcs, tt.filter)
+			if len(filtered) != len(tt.expectedNames) {
+				t.Fatalf("filtered test cases count = %d, want %d", len(filtered), len(tt.expectedNames))
+			}
+			for i, name := range tt.expectedNames {
+				if filtered[i].GetName() != name {
+					t.Errorf("filtered test case %d = %s, want %s", i, filtered[i].GetName(), name)
+				}
+				if filtered[i].StepFilter != nil {
+					t.Errorf("step filter = %v, want nil", filtered[i].StepFilter)
+				}
+			}
+		})
+	}
+}
+
+func TestStepFilter_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   *StepFilter
+		step     string
+		expected bool
+	}{
+		{name: "nil filter", step: "createTable", expected: true},
+		{name: "include", filter: &StepFilter{Include: []string{"*InsertEmptyTable"}}, step: "1000xInsertEmptyTable", expected: true},
+		{name: "not included", filter: &StepFilter{Include: []string{"*InsertEmptyTable"}}, step: "createTable", expected: false},
+		{name: "exclude", filter: &StepFilter{Exclude: []string{"10000*"}}, step: "10000000xInsertEmptyTable", expected: false},
+		{name: "matrix case name", filter: &StepFilter{Include: []string{"postgres[image=postgres:14*]"}}, step: "postgres[image=postgres:14,env-vars=0]", expected: true},
+		{name: "bracket isn't character class", filter: &StepFilter{Include: []string{"loadFixture[a]"}}, step: "loadFixturea", expected: false},
+		{name: "star crosses slash", filter: &StepFilter{Include: []string{"*postgres:14"}}, step: "library/postgres:14", expected: true},
+		{name: "question mark", filter: &StepFilter{Include: []string{"1?xInsert"}}, step: "10xInsert", expected: true},
+		{name: "escaped star", filter: &StepFilter{Include: []string{`10\*`}}, step: "10000", expected: false},
+		{name: "escaped star literal", filter: &StepFilter{Include: []string{`10\*`}}, step: "10*", expected: true},
+		{name: "star backtracking", filter: &StepFilter{Include: []string{"*x*Table"}}, step: "1000xInsertxEmptyTable", expected: true},
+		{name: "exclude has priority", filter: &StepFilter{Include: []string{"*Insert*"}, Exclude: []string{"1000x*"}}, step: "1000xInsertEmptyTable", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := tt.filter.Match(tt.step); actual != tt.expected {
+				t.Errorf("Match(%s) = %v, want %v", tt.step, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   FilterConfig
+		expected error
+	}{
+		{name: "empty"},
+		{name: "valid", filter: FilterConfig{IncludeCases: []string{"postgres[*]"}, ExcludeSteps: []string{`10\*`}}},
+		{name: "trailing escape", filter: FilterConfig{IncludeSteps: []string{`insert\`}}, expected: INVALID_FILTER_PATTERN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); err != tt.expected {
+				t.Errorf("Validate() error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package domain
+
+type PullPolicy string
+
+const (
+	// Pull image before every test case
+	PullPolicy_Always = "always"
+	// Pull image only if it is absent locally
+	PullPolicy_IfNotPresent = "if-not-present"
+	// Never pull image. Image must be present locally
+	PullPolicy_Never = "never"
+)
+
+// ImageInfo is a metadata of the image used for the test case
+type ImageInfo struct {
+	Image       string `json:"image"`
+	Digest      string `json:"digest,omitempty"`
+	SizeInBytes int64  `json:"size-in-bytes"`
+	LayersCount int    `json:"layers-count"`
+	// Image was pulled from registry for the test case
+	Pulled               bool  `json:"pulled"`
+	PullDurationInMicros int64 `json:"pull-duration-in-micros"`
+}
@@ -3,34 +3,172 @@ package domain
 type MetricType string
 
 const (
-	MetricType_Duration            = "duration"
-	MetricType_CpuUsage            = "cpuUsage"
-	MetricType_MemoryUsage         = "memoryUsage"
-	MetricType_MemoryUsageDiff     = "memoryUsageDiff"
-	MetricType_StorageReadUsage    = "storageReadUsage"
-	MetricType_StorageWriteUsage   = "storageWriteUsage"
-	MetricType_NetworkReceiveUsage = "networkReceiveUsage"
-	MetricType_NetworkSendUsage    = "networkSendUsage"
+	MetricType_Duration               = "duration"
+	MetricType_CpuUsage               = "cpuUsage"
+	MetricType_CpuUserUsage           = "cpuUserUsage"
+	MetricType_CpuSystemUsage         = "cpuSystemUsage"
+	MetricType_CpuUtilization         = "cpuUtilization"
+	MetricType_CpuThrottledPeriods    = "cpuThrottledPeriods"
+	MetricType_CpuThrottledTime       = "cpuThrottledTime"
+	MetricType_MemoryUsage            = "memoryUsage"
+	MetricType_MemoryUsageDiff        = "memoryUsageDiff"
+	MetricType_MemoryWorkingSet       = "memoryWorkingSet"
+	MetricType_MemoryRss              = "memoryRss"
+	MetricType_MemoryCache            = "memoryCache"
+	MetricType_MemoryPeakUsage        = "memoryPeakUsage"
+	MetricType_MemoryUsagePercent     = "memoryUsagePercent"
+	MetricType_MemoryPageFaults       = "memoryPageFaults"
+	MetricType_MemoryMajorPageFaults  = "memoryMajorPageFaults"
+	MetricType_StorageReadUsage       = "storageReadUsage"
+	MetricType_StorageWriteUsage      = "storageWriteUsage"
+	MetricType_StorageReadOperations  = "storageReadOperations"
+	MetricType_StorageWriteOperations = "storageWriteOperations"
+	MetricType_NetworkReceiveUsage    = "networkReceiveUsage"
+	MetricType_NetworkSendUsage       = "networkSendUsage"
+	MetricType_NetworkReceivePackets  = "networkReceivePackets"
+	MetricType_NetworkSendPackets     = "networkSendPackets"
+	MetricType_NetworkReceiveDropped  = "networkReceiveDropped"
+	MetricType_NetworkSendDropped     = "networkSendDropped"
+	MetricType_NetworkReceiveErrors   = "networkReceiveErrors"
+	MetricType_NetworkSendErrors      = "networkSendErrors"
+	MetricType_Tps                    = "tps"
+	MetricType_LatencyP50             = "latencyP50"
+	MetricType_LatencyP95             = "latencyP95"
+	MetricType_LatencyP99             = "latencyP99"
+	MetricType_ReplicationLag         = "replicationLag"
+	MetricType_OperationsThroughput   = "operationsThroughput"
+	MetricType_PayloadThroughput      = "payloadThroughput"
+	MetricType_OperationLatency       = "operationLatency"
+	MetricType_ClientUserCpuTime      = "clientUserCpuTime"
+	MetricType_ClientSystemCpuTime    = "clientSystemCpuTime"
+	MetricType_ClientAllocatedSize    = "clientAllocatedSize"
+	MetricType_ClientAllocations      = "clientAllocations"
+	MetricType_ClientGcPauseTime      = "clientGcPauseTime"
+	MetricType_ClientGcCycles         = "clientGcCycles"
 )
 
 type MetricMeta struct {
 	Name                string              `json:"name"`
 	UnitOfMeasurePrefix UnitOfMeasurePrefix `json:"uom-prefix"`
 	UnitOfMeasure       UnitOfMeasure       `json:"uom"`
+	// Throughput metrics are better when higher. Other metrics are better when lower
+	HigherIsBetter bool `json:"higher-is-better,omitempty"`
 }
 
 var (
-	MetricMeta_Duration            = &MetricMeta{Name: "duration", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Micro, UnitOfMeasure: UnitOfMeasure_Second}
-	MetricMeta_CpuUsage            = &MetricMeta{Name: "cpuUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Nano, UnitOfMeasure: UnitOfMeasure_Second}
+	MetricMeta_Duration       = &MetricMeta{Name: "duration", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Micro, UnitOfMeasure: UnitOfMeasure_Second}
+	MetricMeta_CpuUsage       = &MetricMeta{Name: "cpuUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Nano, UnitOfMeasure: UnitOfMeasure_Second}
+	MetricMeta_CpuUserUsage   = &MetricMeta{Name: "cpuUserUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Nano, UnitOfMeasure: UnitOfMeasure_Second}
+	MetricMeta_CpuSystemUsage = &MetricMeta{Name: "cpuSystemUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Nano, UnitOfMeasure: UnitOfMeasure_Second}
+	// CPU usage relative to the capacity of all online CPUs during the step
+	MetricMeta_CpuUtilization      = &MetricMeta{Name: "cpuUtilization", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Percent}
+	MetricMeta_CpuThrottledPeriods = &MetricMeta{Name: "cpuThrottledPeriods", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
+	MetricMeta_CpuThrottledTime    = &MetricMeta{Name: "cpuThrottledTime", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Nano, UnitOfMeasure: UnitOfMeasure_Second}
 	MetricMeta_MemoryUsage         = &MetricMeta{Name: "memoryUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
 	MetricMeta_MemoryUsageDiff     = &MetricMeta{Name: "memoryUsageDiff", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
-	MetricMeta_StorageReadUsage    = &MetricMeta{Name: "storageReadUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
-	MetricMeta_StorageWriteUsage   = &MetricMeta{Name: "storageWriteUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
-	MetricMeta_NetworkReceiveUsage = &MetricMeta{Name: "networkReceiveUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
-	MetricMeta_NetworkSendUsage    = &MetricMeta{Name: "networkSendUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
+	MetricMeta_MemoryWorkingSet    = &MetricMeta{Name: "memoryWorkingSet", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
+	MetricMeta_MemoryRss           = &MetricMeta{Name: "memoryRss", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
+	MetricMeta_MemoryCache         = &MetricMeta{Name: "memoryCache", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
+	MetricMeta_MemoryPeakUsage     = &MetricMeta{Name: "memoryPeakUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
+	// Working set relative to the container memory limit
+	MetricMeta_MemoryUsagePercent     = &MetricMeta{Name: "memoryUsagePercent", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Percent}
+	MetricMeta_MemoryPageFaults       = &MetricMeta{Name: "memoryPageFaults", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
+	MetricMeta_MemoryMajorPageFaults  = &MetricMeta{Name: "memoryMajorPageFaults", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
+	MetricMeta_StorageReadUsage       = &MetricMeta{Name: "storageReadUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
+	MetricMeta_StorageWriteUsage      = &MetricMeta{Name: "storageWriteUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
+	MetricMeta_StorageReadOperations  = &MetricMeta{Name: "storageReadOperations", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
+	MetricMeta_StorageWriteOperations = &MetricMeta{Name: "storageWriteOperations", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
+	MetricMeta_NetworkReceiveUsage    = &MetricMeta{Name: "networkReceiveUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
+	MetricMeta_NetworkSendUsage       = &MetricMeta{Name: "networkSendUsage", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
+	MetricMeta_NetworkReceivePackets  = &MetricMeta{Name: "networkReceivePackets", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
+	MetricMeta_NetworkSendPackets     = &MetricMeta{Name: "networkSendPackets", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
+	MetricMeta_NetworkReceiveDropped  = &MetricMeta{Name: "networkReceiveDropped", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
+	MetricMeta_NetworkSendDropped     = &MetricMeta{Name: "networkSendDropped", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
+	MetricMeta_NetworkReceiveErrors   = &MetricMeta{Name: "networkReceiveErrors", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
+	MetricMeta_NetworkSendErrors      = &MetricMeta{Name: "networkSendErrors", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
+	MetricMeta_Tps                    = &MetricMeta{Name: "tps", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_TransactionPerSecond, HigherIsBetter: true}
+	MetricMeta_LatencyP50             = &MetricMeta{Name: "latencyP50", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Micro, UnitOfMeasure: UnitOfMeasure_Second}
+	MetricMeta_LatencyP95             = &MetricMeta{Name: "latencyP95", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Micro, UnitOfMeasure: UnitOfMeasure_Second}
+	MetricMeta_LatencyP99             = &MetricMeta{Name: "latencyP99", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Micro, UnitOfMeasure: UnitOfMeasure_Second}
+	MetricMeta_ReplicationLag         = &MetricMeta{Name: "replicationLag", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Micro, UnitOfMeasure: UnitOfMeasure_Second}
+	// Derived from the step duration and its declared operations count and payload size
+	MetricMeta_OperationsThroughput = &MetricMeta{Name: "operationsThroughput", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_OperationPerSecond, HigherIsBetter: true}
+	MetricMeta_PayloadThroughput    = &MetricMeta{Name: "payloadThroughput", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_BytePerSecond, HigherIsBetter: true}
+	MetricMeta_OperationLatency     = &MetricMeta{Name: "operationLatency", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Micro, UnitOfMeasure: UnitOfMeasure_Second}
+	// Resources usage of the cott process itself. High values mean that the client is the bottleneck of the step
+	MetricMeta_ClientUserCpuTime   = &MetricMeta{Name: "clientUserCpuTime", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Nano, UnitOfMeasure: UnitOfMeasure_Second}
+	MetricMeta_ClientSystemCpuTime = &MetricMeta{Name: "clientSystemCpuTime", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Nano, UnitOfMeasure: UnitOfMeasure_Second}
+	MetricMeta_ClientAllocatedSize = &MetricMeta{Name: "clientAllocatedSize", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Byte}
+	MetricMeta_ClientAllocations   = &MetricMeta{Name: "clientAllocations", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
+	MetricMeta_ClientGcPauseTime   = &MetricMeta{Name: "clientGcPauseTime", UnitOfMeasurePrefix: UnitOfMeasurePrefix_Nano, UnitOfMeasure: UnitOfMeasure_Second}
+	MetricMeta_ClientGcCycles      = &MetricMeta{Name: "clientGcCycles", UnitOfMeasurePrefix: UnitOfMeasurePrefix_None, UnitOfMeasure: UnitOfMeasure_Piece}
 )
 
+// MetricMetas contains metas of all metrics collected by cott
+var MetricMetas = []*MetricMeta{
+	MetricMeta_Duration,
+	MetricMeta_CpuUsage,
+	MetricMeta_CpuUserUsage,
+	MetricMeta_CpuSystemUsage,
+	MetricMeta_CpuUtilization,
+	MetricMeta_CpuThrottledPeriods,
+	MetricMeta_CpuThrottledTime,
+	MetricMeta_MemoryUsage,
+	MetricMeta_MemoryUsageDiff,
+	MetricMeta_MemoryWorkingSet,
+	MetricMeta_MemoryRss,
+	MetricMeta_MemoryCache,
+	MetricMeta_MemoryPeakUsage,
+	MetricMeta_MemoryUsagePercent,
+	MetricMeta_MemoryPageFaults,
+	MetricMeta_MemoryMajorPageFaults,
+	MetricMeta_StorageReadUsage,
+	MetricMeta_StorageWriteUsage,
+	MetricMeta_StorageReadOperations,
+	MetricMeta_StorageWriteOperations,
+	MetricMeta_NetworkReceiveUsage,
+	MetricMeta_NetworkSendUsage,
+	MetricMeta_NetworkReceivePackets,
+	MetricMeta_NetworkSendPackets,
+	MetricMeta_NetworkReceiveDropped,
+	MetricMeta_NetworkSendDropped,
+	MetricMeta_NetworkReceiveErrors,
+	MetricMeta_NetworkSendErrors,
+	MetricMeta_Tps,
+	MetricMeta_LatencyP50,
+	MetricMeta_LatencyP95,
+	MetricMeta_LatencyP99,
+	MetricMeta_ReplicationLag,
+	MetricMeta_OperationsThroughput,
+	MetricMeta_PayloadThroughput,
+	MetricMeta_OperationLatency,
+	MetricMeta_ClientUserCpuTime,
+	MetricMeta_ClientSystemCpuTime,
+	MetricMeta_ClientAllocatedSize,
+	MetricMeta_ClientAllocations,
+	MetricMeta_ClientGcPauseTime,
+	MetricMeta_ClientGcCycles,
+}
+
+// FindMetricMeta returns meta of the metric by name. Nil if the metric is unknown
+func FindMetricMeta(name string) *MetricMeta {
+	for _, meta := range MetricMetas {
+		if meta.Name == name {
+			return meta
+		}
+	}
+	return nil
+}
+
 type Metric struct {
-	Meta  MetricMeta `json:"meta"`
-	Value float64    `json:"value"`
+	Meta MetricMeta `json:"meta"`
+	// Alias of the container in the test case topology. Empty for step level metrics and single container cases
+	Container string `json:"container,omitempty"`
+	// Network interface of the per-interface network metrics. Empty for metrics summed across interfaces
+	Interface string `json:"interface,omitempty"`
+	// Block device "major:minor" number of the per-device storage metrics. Empty for metrics summed across devices
+	Device string  `json:"device,omitempty"`
+	Value  float64 `json:"value"`
+	// Values excluded from the Value calculation
+	Outliers []float64 `json:"outliers,omitempty"`
 }
@@ -0,0 +1,146 @@
+package domain
+
+import "path/filepath"
+
+type MountType string
+
+const (
+	// Named or anonymous volume managed by the container engine
+	MountType_Volume = "volume"
+	// Host directory
+	MountType_Bind = "bind"
+	// In-memory filesystem
+	MountType_Tmpfs = "tmpfs"
+
+	DEFAULT_VOLUME_DRIVER = "local"
+)
+
+// Mount describes storage mounted into the container
+type Mount struct {
+	Type MountType `json:"type"`
+	// Volume name prefix or host path. Named volume is created for every launch and removed with the container.
+	// Anonymous volume is created if empty for the volume type
+	Source string `json:"source,omitempty"`
+	// Path in the container. Component data directory is used if empty
+	Target string `json:"target"`
+	// Volume driver
+	Driver        string            `json:"driver,omitempty"`
+	DriverOptions map[string]string `json:"driver-options,omitempty"`
+	ReadOnly      bool              `json:"read-only,omitempty"`
+	// Size limit of the tmpfs. Unlimited if 0
+	TmpfsSizeInBytes int64 `json:"tmpfs-size-in-bytes,omitempty"`
+}
+
+// StorageInfo describes storage of the component under test
+type StorageInfo struct {
+	// Storage driver of the container writable layer, e.g. overlay2
+	Driver string  `json:"driver"`
+	Mounts []Mount `json:"mounts,omitempty"`
+}
+
+func (m *Mount) GetDriver() string {
+	if m.Type != MountType_Volume {
+		return ""
+	} else if m.Driver == "" {
+		return DEFAULT_VOLUME_DRIVER
+	} else {
+		return m.Driver
+	}
+}
+
+func (m *Mount) GetTarget(componentType ComponentType) string {
+	if m.Target == "" {
+		return componentType.DataDirectory()
+	} else {
+		return m.Target
+	}
+}
+
+// DataDirectory returns component data directory in the official image
+func (ct ComponentType) DataDirectory() string {
+	switch ct {
+	case ComponentType_Postgres:
+		return "/var/lib/postgresql/data"
+	case ComponentType_Kafka:
+		return "/var/lib/kafka/data"
+	default:
+		return ""
+	}
+}
+
+// resolveMounts returns mounts with defaults resolved for the component
+func resolveMounts(mounts []Mount, componentType ComponentType) []Mount {
+	var resolved []Mount
+	for _, m := range mounts {
+		m.Target = m.GetTarget(componentType)
+		m.Driver = m.GetDriver()
+		resolved = append(resolved, m)
+	}
+	return resolved
+}
+
+// Validate checks that mount could be passed to the container engine
+func (m *Mount) Validate() error {
+	switch m.Type {
+	case MountType_Volume, MountType_Tmpfs:
+	case MountType_Bind:
+		if m.Source == "" {
+			return MOUNT_SOURCE_IS_REQUIRED
+		}
+		// Container engines resolve bind sources on the daemon side, so relative paths are rejected
+		if !filepath.IsAbs(m.Source) {
+			return MOUNT_SOURCE_MUST_BE_ABSOLUTE
+		}
+	default:
+		return UNKNOWN_MOUNT_TYPE
+	}
+
+	if m.Target == "" {
+		return MOUNT_TARGET_IS_REQUIRED
+	}
+	return nil
+}
+
+// ResolveHostPaths makes relative host paths of bind mounts, config files and init scripts absolute.
+// Paths are relative to baseDir, e.g. directory of the config file.
+func (tc *TestCase) ResolveHostPaths(baseDir string) error {
+	var err error
+	for i := range tc.Mounts {
+		if err = resolveBindSource(&tc.Mounts[i], baseDir); err != nil {
+			return err
+		}
+	}
+	for i := range tc.Topology {
+		for j := range tc.Topology[i].Mounts {
+			if err = resolveBindSource(&tc.Topology[i].Mounts[j], baseDir); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range tc.ConfigFiles {
+		if tc.ConfigFiles[i].Source, err = resolveHostPath(tc.ConfigFiles[i].Source, baseDir); err != nil {
+			return err
+		}
+	}
+	if tc.InitScriptsDirectory, err = resolveHostPath(tc.InitScriptsDirectory, baseDir); err != nil {
+		return err
+	}
+	return nil
+}
+
+func resolveBindSource(m *Mount, baseDir string) error {
+	if m.Type != MountType_Bind {
+		return nil
+	}
+	var err error
+	m.Source, err = resolveHostPath(m.Source, baseDir)
+	return err
+}
+
+// resolveHostPath returns absolute path. Empty and absolute paths are returned as is
+func resolveHostPath(path, baseDir string) (string, error) {
+	if path == "" || filepath.IsAbs(path) {
+		return path, nil
+	}
+	return filepath.Abs(filepath.Join(baseDir, path))
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMount_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		mount       Mount
+		expectedErr error
+	}{
+		{name: "volume", mount: Mount{Type: MountType_Volume, Target: "/data"}},
+		{name: "tmpfs", mount: Mount{Type: MountType_Tmpfs, Target: "/data", TmpfsSizeInBytes: 1 << 20}},
+		{name: "bind", mount: Mount{Type: MountType_Bind, Source: "/tmp", Target: "/data"}},
+		{name: "bind without source", mount: Mount{Type: MountType_Bind, Target: "/data"}, expectedErr: MOUNT_SOURCE_IS_REQUIRED},
+		{name: "bind with relative source", mount: Mount{Type: MountType_Bind, Source: "./data", Target: "/data"}, expectedErr: MOUNT_SOURCE_MUST_BE_ABSOLUTE},
+		{name: "without target", mount: Mount{Type: MountType_Volume}, expectedErr: MOUNT_TARGET_IS_REQUIRED},
+		{name: "unknown type", mount: Mount{Type: "npipe", Target: "/data"}, expectedErr: UNKNOWN_MOUNT_TYPE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.mount.Validate(); err != tt.expectedErr {
+				t.Errorf("Validate() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestTestCase_Mounts(t *testing.T) {
+	tc := TestCase{
+		ComponentType: ComponentType_Postgres,
+		Mounts: []Mount{
+			{Type: MountType_Volume, Source: "pgdata"},
+			{Type: MountType_Bind, Source: "/tmp/conf", Target: "/etc/postgresql"},
+			{Type: MountType_Tmpfs, Target: "/tmp"},
+		},
+		Replication: &ReplicationConfig{},
+	}
+
+	main := tc.MainContainer()
+	if len(main.Mounts) != 3 {
+		t.Fatalf("main container mounts = %v, want 3", main.Mounts)
+	}
+	if main.Mounts[0].Target != "/var/lib/postgresql/data" || main.Mounts[0].Driver != DEFAULT_VOLUME_DRIVER {
+		t.Errorf("main container volume = %+v, want defaults resolved", main.Mounts[0])
+	}
+	if main.Mounts[2].Driver != "" {
+		t.Errorf("tmpfs driver = %s, want empty", main.Mounts[2].Driver)
+	}
+
+	replica := tc.ReplicaContainer()
+	if len(replica.Mounts) != 2 {
+		t.Fatalf("replica mounts = %v, want bind mount skipped", replica.Mounts)
+	}
+	if replica.Mounts[0].Source != "" {
+		t.Errorf("replica volume source = %s, want anonymous volume", replica.Mounts[0].Source)
+	}
+	if tc.Mounts[0].Target != "" {
+		t.Errorf("test case mounts were modified")
+	}
+}
+
+func TestTestCase_ResolveHostPaths(t *testing.T) {
+	baseDir := t.TempDir()
+	tc := TestCase{
+		Mounts:               []Mount{{Type: MountType_Bind, Source: "./data"}, {Type: MountType_Volume, Source: "pgdata"}, {Type: MountType_Bind, Source: "/srv"}},
+		Topology:             []TopologyContainer{{Alias: "proxy", Mounts: []Mount{{Type: MountType_Bind, Source: "proxy.conf"}}}},
+		ConfigFiles:          []ConfigFile{{Source: "./postgresql.conf", Target: "/etc/postgresql/postgresql.conf"}},
+		InitScriptsDirectory: "./initdb",
+	}
+
+	if err := tc.ResolveHostPaths(baseDir); err != nil {
+		t.Fatalf("ResolveHostPaths() error = %v", err)
+	}
+
+	actual := []string{tc.Mounts[0].Source, tc.Mounts[1].Source, tc.Mounts[2].Source, tc.Topology[0].Mounts[0].Source, tc.ConfigFiles[0].Source, tc.InitScriptsDirectory}
+	expected := []string{
+		filepath.Join(baseDir, "data"),
+		"pgdata",
+		"/srv",
+		filepath.Join(baseDir, "proxy.conf"),
+		filepath.Join(baseDir, "postgresql.conf"),
+		filepath.Join(baseDir, "initdb"),
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("host paths = %v, want %v", actual, expected)
+	}
+}
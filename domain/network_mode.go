@@ -0,0 +1,11 @@
+package domain
+
+// NetworkMode defines how the main container is connected to the host network
+type NetworkMode string
+
+const (
+	// Container has own network namespace. Port is published on the host
+	NetworkMode_Bridge = "bridge"
+	// Container shares host network namespace. Component should listen on the test case port itself
+	NetworkMode_Host = "host"
+)
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+type OutlierDetection string
+
+const (
+	OutlierDetection_None = ""
+	// Values outside of [Q1 - 1.5*IQR, Q3 + 1.5*IQR]
+	OutlierDetection_Iqr = "iqr"
+	// Values with modified z-score based on median absolute deviation greater than 3.5
+	OutlierDetection_Mad = "mad"
+)
+
+const (
+	IQR_OUTLIER_FACTOR          = 1.5
+	MAD_OUTLIER_THRESHOLD       = 3.5
+	MAD_NORMAL_CONSISTENCY_COEF = 0.6745
+)
+
+// SplitOutliers returns values without outliers and outliers separately
+func (od OutlierDetection) SplitOutliers(values []float64) (inliers []float64, outliers []float64) {
+	if len(values) == 0 {
+		return values, nil
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var isOutlier func(v float64) bool
+
+	switch od {
+
+	case OutlierDetection_Iqr:
+		q1 := stat.Quantile(0.25, stat.Empirical, sorted, nil)
+		q3 := stat.Quantile(0.75, stat.Empirical, sorted, nil)
+		iqr := q3 - q1
+		isOutlier = func(v float64) bool {
+			return v < q1-IQR_OUTLIER_FACTOR*iqr || v > q3+IQR_OUTLIER_FACTOR*iqr
+		}
+
+	case OutlierDetection_Mad:
+		median := stat.Quantile(0.5, stat.Empirical, sorted, nil)
+		deviations := make([]float64, len(sorted))
+		for i, v := range sorted {
+			deviations[i] = math.Abs(v - median)
+		}
+		sort.Float64s(deviations)
+		mad := stat.Quantile(0.5, stat.Empirical, deviations, nil)
+		isOutlier = func(v float64) bool {
+			return mad > 0 && MAD_NORMAL_CONSISTENCY_COEF*math.Abs(v-median)/mad > MAD_OUTLIER_THRESHOLD
+		}
+
+	default:
+		return values, nil
+	}
+
+	for _, v := range values {
+		if isOutlier(v) {
+			outliers = append(outliers, v)
+		} else {
+			inliers = append(inliers, v)
+		}
+	}
+
+	return inliers, outliers
+}
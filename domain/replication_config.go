@@ -0,0 +1,65 @@
+package domain
+
+import "strconv"
+
+const (
+	REPLICA_CONTAINER_ALIAS = "replica"
+	// Replica is promoted on the file creation before Postgres 12, which has no pg_promote()
+	POSTGRES_PROMOTE_TRIGGER_FILE = "/tmp/cott_promote"
+	// Oldest Postgres version with the pg_current_wal_lsn() and pg_last_wal_replay_lsn() functions
+	POSTGRES_MIN_REPLICATION_SERVER_VERSION = 100000
+	// Oldest Postgres version without recovery.conf and with pg_promote()
+	POSTGRES_STANDBY_SIGNAL_SERVER_VERSION = 120000
+)
+
+// ReplicationConfig describes Postgres streaming replica of the main container.
+// Replica is cloned from the main container by pg_basebackup on start.
+type ReplicationConfig struct {
+	ReplicaAlias string `json:"replica-alias"`
+	// Port on the host to connect to replica. Main container port + 1 if not set
+	ReplicaHostPort uint16 `json:"replica-host-port"`
+	// Measure replica promotion time. Replica is promoted at the end of the round and can't be reused in the next one
+	Failover bool `json:"failover"`
+}
+
+func (c *ReplicationConfig) GetReplicaAlias() string {
+	if c.ReplicaAlias == "" {
+		return REPLICA_CONTAINER_ALIAS
+	} else {
+		return c.ReplicaAlias
+	}
+}
+
+func (c *ReplicationConfig) GetReplicaHostPort(tc *TestCase) uint16 {
+	if c.ReplicaHostPort == 0 {
+		return tc.Port + 1
+	} else {
+		return c.ReplicaHostPort
+	}
+}
+
+// postgresPrimaryCmd allows replication connections and starts the primary with the server command
+func postgresPrimaryCmd(serverCmd []string) []string {
+	// Own pg_hba.conf is used, because init scripts directory could be mounted read-only
+	script := `printf 'local all all trust\nhost all all all md5\nhost replication all all md5\n' > /tmp/cott_pg_hba.conf` +
+		` && exec docker-entrypoint.sh "$@" -c hba_file=/tmp/cott_pg_hba.conf`
+	return append([]string{"bash", "-c", script, "bash"}, postgresServerCmd(serverCmd)...)
+}
+
+// postgresReplicaCmd clones primary data directory and starts replica in standby mode with the server command.
+// pg_basebackup -R writes recovery.conf before Postgres 12, so the promote trigger file is added to it.
+func postgresReplicaCmd(primaryAlias string, primaryPort uint16, serverCmd []string) []string {
+	script := `until PGPASSWORD="$POSTGRES_PASSWORD" pg_basebackup -h ` + primaryAlias + ` -p ` + strconv.FormatUint(uint64(primaryPort), 10) +
+		` -U "$POSTGRES_USER" -D "$PGDATA" -R -X stream; do rm -rf "$PGDATA"/*; sleep 1; done` +
+		` && if [ -f "$PGDATA/recovery.conf" ]; then echo "trigger_file = '` + POSTGRES_PROMOTE_TRIGGER_FILE + `'" >> "$PGDATA/recovery.conf"; fi` +
+		` && exec docker-entrypoint.sh "$@"`
+	return append([]string{"bash", "-c", script, "bash"}, postgresServerCmd(serverCmd)...)
+}
+
+func postgresServerCmd(serverCmd []string) []string {
+	if len(serverCmd) == 0 {
+		return ComponentType(ComponentType_Postgres).DefaultCmd()
+	} else {
+		return serverCmd
+	}
+}
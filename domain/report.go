@@ -1,6 +1,8 @@
 package domain
 
 type Report struct {
+	// Environment of the run. Nil until the run is finished
+	RunInfo         *RunInfo           `json:"run-info,omitempty"`
 	TestCaseResults []*TestCaseResults `json:"test-case-results"`
 }
 
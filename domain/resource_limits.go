@@ -0,0 +1,22 @@
+package domain
+
+import "strconv"
+
+// ResourceLimits restricts resources of the container. Zero values mean unlimited
+type ResourceLimits struct {
+	// Count of CPUs, e.g. 1.5
+	Cpus          float64 `json:"cpus,omitempty"`
+	MemoryInBytes int64   `json:"memory-in-bytes,omitempty"`
+}
+
+func (rl ResourceLimits) String() string {
+	cpus := "unlimited"
+	if rl.Cpus > 0 {
+		cpus = strconv.FormatFloat(rl.Cpus, 'f', -1, 64)
+	}
+	memory := "unlimited"
+	if rl.MemoryInBytes > 0 {
+		memory = strconv.FormatInt(rl.MemoryInBytes, 10)
+	}
+	return "cpus:" + cpus + " memory:" + memory
+}
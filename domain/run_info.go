@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// RunInfo describes environment of the cott run, so results from different machines aren't compared blindly
+type RunInfo struct {
+	CottVersion string    `json:"cott-version"`
+	GoVersion   string    `json:"go-version"`
+	StartTime   time.Time `json:"start-time"`
+	EndTime     time.Time `json:"end-time"`
+	Host        HostInfo  `json:"host"`
+	// Nil if engine info couldn't be read
+	Engine *EngineInfo `json:"engine,omitempty"`
+	// Host resources usage during the run. Nil if /proc isn't available
+	HostLoad *HostLoad `json:"host-load,omitempty"`
+	// Loaded config with defaults and env vars applied. Test cases are the run ones: expanded by matrices and filtered
+	Config Config `json:"config"`
+}
+
+// HostInfo describes host of the cott process. Containers run on another host with remote engine
+type HostInfo struct {
+	Hostname      string `json:"hostname"`
+	Os            string `json:"os"`
+	Arch          string `json:"arch"`
+	KernelVersion string `json:"kernel-version,omitempty"`
+	CpuModel      string `json:"cpu-model,omitempty"`
+	CpuCores      int    `json:"cpu-cores"`
+	MemoryInBytes uint64 `json:"memory-in-bytes,omitempty"`
+}
+
+// EngineInfo describes container engine
+type EngineInfo struct {
+	Name          string `json:"name"`
+	Version       string `json:"version"`
+	StorageDriver string `json:"storage-driver"`
+	// "1" or "2"
+	CgroupVersion string `json:"cgroup-version,omitempty"`
+}
+
+// HostLoad is the host resources usage between the run start and end, including processes outside of the test.
+// High values mean noisy neighbors, which make results less reliable.
+type HostLoad struct {
+	// Busy time of all CPUs relative to their capacity
+	CpuUtilization float64 `json:"cpu-utilization"`
+	// Time of CPUs waiting for IO relative to their capacity
+	CpuIoWait float64 `json:"cpu-io-wait"`
+	// Time stolen by the hypervisor relative to CPUs capacity
+	CpuSteal       float64 `json:"cpu-steal"`
+	DiskReadBytes  uint64  `json:"disk-read-bytes"`
+	DiskWriteBytes uint64  `json:"disk-write-bytes"`
+	// Load averages for 1, 5 and 15 minutes at the run end
+	LoadAverage []float64 `json:"load-average,omitempty"`
+}
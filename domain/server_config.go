@@ -0,0 +1,72 @@
+package domain
+
+import "sort"
+
+// ConfigFile is a host file mounted read-only into the main container
+type ConfigFile struct {
+	// Path on the host
+	Source string `json:"source"`
+	// Path in the container
+	Target string `json:"target"`
+}
+
+// DefaultCmd returns CMD of the component official image
+func (ct ComponentType) DefaultCmd() []string {
+	switch ct {
+	case ComponentType_Postgres:
+		return []string{"postgres"}
+	default:
+		return nil
+	}
+}
+
+// InitScriptsDirectory returns directory with scripts executed on the first start of the component official image
+func (ct ComponentType) InitScriptsDirectory() string {
+	switch ct {
+	case ComponentType_Postgres:
+		return "/docker-entrypoint-initdb.d"
+	default:
+		return ""
+	}
+}
+
+// ServerCmd returns command of the component server with parameters applied. Image CMD is used if empty
+func (tc *TestCase) ServerCmd() []string {
+	cmd := tc.Cmd
+	if len(tc.Parameters) == 0 {
+		return cmd
+	}
+	if len(cmd) == 0 {
+		cmd = tc.ComponentType.DefaultCmd()
+	}
+
+	keys := make([]string, 0, len(tc.Parameters))
+	for k := range tc.Parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Copy to prevent modification of the test case command
+	cmd = append([]string{}, cmd...)
+	for _, k := range keys {
+		switch tc.ComponentType {
+		case ComponentType_Postgres:
+			cmd = append(cmd, "-c", k+"="+tc.Parameters[k])
+		default:
+			cmd = append(cmd, "--"+k+"="+tc.Parameters[k])
+		}
+	}
+	return cmd
+}
+
+// serverMounts returns mounts of the config files and init scripts directory
+func (tc *TestCase) serverMounts() []Mount {
+	var mounts []Mount
+	for _, f := range tc.ConfigFiles {
+		mounts = append(mounts, Mount{Type: MountType_Bind, Source: f.Source, Target: f.Target, ReadOnly: true})
+	}
+	if tc.InitScriptsDirectory != "" {
+		mounts = append(mounts, Mount{Type: MountType_Bind, Source: tc.InitScriptsDirectory, Target: tc.ComponentType.InitScriptsDirectory(), ReadOnly: true})
+	}
+	return mounts
+}
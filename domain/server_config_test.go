@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTestCase_ServerCmd(t *testing.T) {
+	tests := []struct {
+		name        string
+		tc          TestCase
+		expectedCmd []string
+	}{
+		{
+			name: "image cmd",
+			tc:   TestCase{ComponentType: ComponentType_Postgres},
+		},
+		{
+			name:        "custom cmd",
+			tc:          TestCase{ComponentType: ComponentType_Postgres, Cmd: []string{"postgres", "-c", "fsync=off"}},
+			expectedCmd: []string{"postgres", "-c", "fsync=off"},
+		},
+		{
+			name:        "parameters",
+			tc:          TestCase{ComponentType: ComponentType_Postgres, Parameters: map[string]string{"work_mem": "64MB", "shared_buffers": "1GB"}},
+			expectedCmd: []string{"postgres", "-c", "shared_buffers=1GB", "-c", "work_mem=64MB"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cmd := tt.tc.ServerCmd(); !reflect.DeepEqual(cmd, tt.expectedCmd) {
+				t.Errorf("ServerCmd() = %v, want %v", cmd, tt.expectedCmd)
+			}
+		})
+	}
+}
+
+func TestTestCase_ServerMounts(t *testing.T) {
+	tc := TestCase{
+		ComponentType:        ComponentType_Postgres,
+		ConfigFiles:          []ConfigFile{{Source: "/tmp/postgresql.conf", Target: "/etc/postgresql/postgresql.conf"}},
+		InitScriptsDirectory: "/tmp/init",
+		Replication:          &ReplicationConfig{},
+	}
+
+	main := tc.MainContainer()
+	expected := []Mount{
+		{Type: MountType_Bind, Source: "/tmp/postgresql.conf", Target: "/etc/postgresql/postgresql.conf", ReadOnly: true},
+		{Type: MountType_Bind, Source: "/tmp/init", Target: "/docker-entrypoint-initdb.d", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(main.Mounts, expected) {
+		t.Errorf("main container mounts = %v, want %v", main.Mounts, expected)
+	}
+
+	replica := tc.ReplicaContainer()
+	if !reflect.DeepEqual(replica.Mounts, expected[:1]) {
+		t.Errorf("replica mounts = %v, want config file only", replica.Mounts)
+	}
+}
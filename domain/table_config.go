@@ -0,0 +1,160 @@
+package domain
+
+// Max bind params count of the single Postgres statement
+const MAX_BIND_PARAMS_COUNT = 65535
+
+type ValueDistribution string
+
+const (
+	// Random integers in [Min, Max]
+	ValueDistribution_Uniform = "uniform"
+	// Integers in [Min, Max] skewed to Min
+	ValueDistribution_Zipfian = "zipfian"
+	// Increasing integers starting from Min
+	ValueDistribution_Sequential = "sequential"
+	// Random letters with length in [Length, MaxLength]
+	ValueDistribution_Text = "text"
+	// JSON object with Length integer fields
+	ValueDistribution_Jsonb = "jsonb"
+	ValueDistribution_Uuid  = "uuid"
+	// Random bytes with length in [Length, MaxLength]
+	ValueDistribution_Bytea = "bytea"
+)
+
+// TableConfig describes table of the insert and select test. Default 11 columns schema is used if columns are empty
+type TableConfig struct {
+	// Data size ladder. 1, 10, ..., 10000000 rows if empty
+	Sizes []int `json:"sizes,omitempty"`
+	// Rows count in the single insert statement. Postgres limits statement with 65535 params
+	BatchSize int            `json:"batch-size"`
+	Columns   []ColumnConfig `json:"columns,omitempty"`
+	// WHERE clause of the select by conditions test. Generated from integer columns if empty
+	SelectConditions string `json:"select-conditions,omitempty"`
+	// Pre-generated data files. Rows are generated in memory before insert steps if not set
+	Dataset *DatasetConfig `json:"dataset,omitempty"`
+}
+
+// ColumnConfig describes table column and its values
+type ColumnConfig struct {
+	Name         string            `json:"name"`
+	Distribution ValueDistribution `json:"distribution"`
+	// SQL type. Derived from distribution if empty
+	Type string `json:"type,omitempty"`
+	Min  int64  `json:"min"`
+	// 255 if not set
+	Max int64 `json:"max"`
+	// Zipfian distribution exponent. Must be greater than 1. 1.1 if not set
+	ZipfExponent float64 `json:"zipf-exponent,omitempty"`
+	// Length of text, bytea or fields count of jsonb values. 16 if not set
+	Length int `json:"length"`
+	// Values length is random in [Length, MaxLength] if set
+	MaxLength int `json:"max-length,omitempty"`
+}
+
+func (c *TableConfig) GetSizes() []int {
+	if c == nil || len(c.Sizes) == 0 {
+		return []int{1, 10, 100, 1000, 10000, 100000, 1000000, 10000000}
+	} else {
+		return c.Sizes
+	}
+}
+
+func (c *TableConfig) GetBatchSize() int {
+	if c == nil || c.BatchSize == 0 {
+		return 1000
+	} else {
+		return c.BatchSize
+	}
+}
+
+// ValidateBatchSize checks that batch size is positive and batch insert of columnsCount columns fits the bind params limit
+func (c *TableConfig) ValidateBatchSize(columnsCount int) error {
+	batchSize := c.GetBatchSize()
+	if batchSize < 0 {
+		return INVALID_BATCH_SIZE
+	}
+	if batchSize*columnsCount > MAX_BIND_PARAMS_COUNT {
+		return BATCH_EXCEEDS_BIND_PARAMS_LIMIT
+	}
+	return nil
+}
+
+// GetType returns SQL type of the column
+func (c *ColumnConfig) GetType() string {
+	if c.Type != "" {
+		return c.Type
+	}
+
+	switch c.Distribution {
+	case ValueDistribution_Text:
+		return "TEXT"
+	case ValueDistribution_Jsonb:
+		return "JSONB"
+	case ValueDistribution_Uuid:
+		return "UUID"
+	case ValueDistribution_Bytea:
+		return "BYTEA"
+	default:
+		return "BIGINT"
+	}
+}
+
+func (c *ColumnConfig) GetMax() int64 {
+	if c.Max == 0 {
+		return 255
+	} else {
+		return c.Max
+	}
+}
+
+func (c *ColumnConfig) GetZipfExponent() float64 {
+	if c.ZipfExponent == 0 {
+		return 1.1
+	} else {
+		return c.ZipfExponent
+	}
+}
+
+func (c *ColumnConfig) GetLength() int {
+	if c.Length == 0 {
+		return 16
+	} else {
+		return c.Length
+	}
+}
+
+// IsInteger checks whether column values are integers
+func (c *ColumnConfig) IsInteger() bool {
+	switch c.Distribution {
+	case ValueDistribution_Uniform, ValueDistribution_Zipfian, ValueDistribution_Sequential:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks column config before table creation
+func (c *ColumnConfig) Validate() error {
+	if c.Name == "" {
+		return COLUMN_NAME_IS_REQUIRED
+	}
+
+	switch c.Distribution {
+	case ValueDistribution_Uniform, ValueDistribution_Sequential:
+	case ValueDistribution_Zipfian:
+		if c.GetZipfExponent() <= 1 {
+			return INVALID_ZIPF_EXPONENT
+		}
+	case ValueDistribution_Text, ValueDistribution_Jsonb, ValueDistribution_Uuid, ValueDistribution_Bytea:
+	default:
+		return UNKNOWN_VALUE_DISTRIBUTION
+	}
+
+	if c.IsInteger() && c.GetMax() < c.Min {
+		return INVALID_VALUES_RANGE
+	}
+	if c.MaxLength != 0 && c.MaxLength < c.GetLength() {
+		return INVALID_VALUES_RANGE
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "testing"
+
+func TestTableConfig_ValidateBatchSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          *TableConfig
+		columnsCount int
+		expectedErr  error
+	}{
+		{name: "default", columnsCount: 11},
+		{name: "max params", cfg: &TableConfig{BatchSize: 6553}, columnsCount: 10},
+		{name: "negative", cfg: &TableConfig{BatchSize: -1}, columnsCount: 11, expectedErr: INVALID_BATCH_SIZE},
+		{name: "params limit", cfg: &TableConfig{BatchSize: 6000}, columnsCount: 11, expectedErr: BATCH_EXCEEDS_BIND_PARAMS_LIMIT},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.ValidateBatchSize(tt.columnsCount); err != tt.expectedErr {
+				t.Errorf("ValidateBatchSize() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -8,13 +8,83 @@ const (
 	ComponentType_Kafka    = "kafka"
 )
 
+const (
+	DEFAULT_DATA_SEED = 1
+)
+
 type TestCase struct {
+	// Unique name of the test case. Generated for the matrix test cases
+	Name          string            `json:"name,omitempty"`
+	Tags          []string          `json:"tags,omitempty"`
 	ComponentType ComponentType     `json:"component-type"`
 	Image         string            `json:"image"`
+	PullPolicy    PullPolicy        `json:"pull-policy"`
 	Port          uint16            `json:"port"`
 	EnvVars       map[string]string `json:"env-vars"`
+	// Alias of the main container in the topology network
+	Alias string `json:"alias,omitempty"`
+	// Aliases of topology containers, which should be started before the main one
+	DependsOn []string `json:"depends-on,omitempty"`
+	// Additional containers, started with the main one in the dedicated network
+	Topology []TopologyContainer `json:"topology,omitempty"`
+	// Storage of the main container. Data lives in the image volumes or container writable layer if empty
+	Mounts []Mount `json:"mounts,omitempty"`
+	// Command overriding the image CMD, e.g. ["postgres", "-c", "shared_buffers=1GB"]
+	Cmd []string `json:"cmd,omitempty"`
+	// Server configuration parameters appended to the command, e.g. shared_buffers: 1GB
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Server configuration parameters values to benchmark. Expanded as matrix axes named by parameters
+	ParametersMatrix map[string][]string `json:"parameters-matrix,omitempty"`
+	// Host files mounted read-only into the main container
+	ConfigFiles []ConfigFile `json:"config-files,omitempty"`
+	// Host directory with scripts executed on the first component start
+	InitScriptsDirectory string `json:"init-scripts-directory,omitempty"`
+	// Network mode of the main container. bridge if empty. Topology and replication require bridge mode
+	NetworkMode NetworkMode `json:"network-mode,omitempty"`
+	// Resource limits of the main and replica containers, which run the component under test.
+	// Other topology containers have their own limits, so helpers don't share the component budget
+	Resources ResourceLimits `json:"resources"`
+	// Test case variants. Test case is expanded into every variants combination
+	Matrix *TestCaseMatrix `json:"matrix,omitempty"`
+	// Matrix values of the expanded test case by axis name
+	MatrixCoordinates map[string]string `json:"matrix-coordinates,omitempty"`
+	// Steps to measure. Set from the config filter
+	StepFilter    *StepFilter `json:"step-filter,omitempty"`
 	Accumulations uint16
-	TestCaseSteps []TestCaseStep `json:"steps"`
+	// Count of scenario runs before accumulations, which are excluded from statistics
+	Warmup uint16
+	// Adaptive accumulations mode. Accumulations count is ignored if set
+	Adaptive         *AdaptiveAccumulationConfig `json:"adaptive,omitempty"`
+	OutlierDetection OutlierDetection            `json:"outlier-detection,omitempty"`
+	Lifecycle        ContainerLifecycle          `json:"lifecycle"`
+	TestCaseSteps    []TestCaseStep              `json:"steps"`
+	// Seed of the generated data. Test cases with the same seed get the same data. DEFAULT_DATA_SEED if not set
+	DataSeed int64 `json:"data-seed,omitempty"`
+	// Schema and data size ladder of the table test. Default schema and ladder are used if not set
+	Table *TableConfig `json:"table,omitempty"`
+	// Data files loaded into the test database before the table test in the declaration order
+	Fixtures []FixtureConfig `json:"fixtures,omitempty"`
+	// Built-in TPC-B workload. Disabled if not set
+	TpcB *TpcBConfig `json:"tpcb,omitempty"`
+	// Postgres streaming replica benchmarks. Disabled if not set
+	Replication *ReplicationConfig `json:"replication,omitempty"`
+}
+
+// GetName returns test case name or image if name isn't set
+func (tc *TestCase) GetName() string {
+	if tc.Name == "" {
+		return tc.Image
+	} else {
+		return tc.Name
+	}
+}
+
+func (tc *TestCase) GetDataSeed() int64 {
+	if tc.DataSeed == 0 {
+		return DEFAULT_DATA_SEED
+	} else {
+		return tc.DataSeed
+	}
 }
 
 func (tc *TestCase) GetAccumulationsCount() uint16 {
@@ -24,3 +94,147 @@ func (tc *TestCase) GetAccumulationsCount() uint16 {
 		return tc.Accumulations
 	}
 }
+
+func (tc *TestCase) GetLifecycle() ContainerLifecycle {
+	if tc.Lifecycle == "" {
+		return ContainerLifecycle_Shared
+	} else {
+		return tc.Lifecycle
+	}
+}
+
+// NeedMoreAccumulations checks whether one more accumulation round is required,
+// when round rounds are already done and their results are in tcra
+func (tc *TestCase) NeedMoreAccumulations(tcra *TestCaseResultsAccumulator, round int) bool {
+	if tc.Adaptive == nil {
+		return round < int(tc.GetAccumulationsCount())
+	}
+
+	if round < int(tc.Adaptive.GetMinAccumulationsCount()) {
+		return true
+	}
+	if round >= int(tc.Adaptive.GetMaxAccumulationsCount()) {
+		return false
+	}
+
+	return !tcra.IsConverged(tc.Adaptive)
+}
+
+func (tc *TestCase) GetPullPolicy() PullPolicy {
+	if tc.PullPolicy == "" {
+		return PullPolicy_Always
+	} else {
+		return tc.PullPolicy
+	}
+}
+
+func (tc *TestCase) GetNetworkMode() NetworkMode {
+	if tc.NetworkMode == "" {
+		return NetworkMode_Bridge
+	} else {
+		return tc.NetworkMode
+	}
+}
+
+func (tc *TestCase) GetAlias() string {
+	if tc.Alias == "" {
+		return MAIN_CONTAINER_ALIAS
+	} else {
+		return tc.Alias
+	}
+}
+
+// WithDefaults returns copy of the test case with defaults of the getters set
+func (tc *TestCase) WithDefaults() TestCase {
+	dtc := *tc
+	dtc.Name = tc.GetName()
+	dtc.DataSeed = tc.GetDataSeed()
+	dtc.Accumulations = tc.GetAccumulationsCount()
+	dtc.Lifecycle = tc.GetLifecycle()
+	dtc.PullPolicy = tc.GetPullPolicy()
+	dtc.NetworkMode = tc.GetNetworkMode()
+	dtc.Alias = tc.GetAlias()
+	return dtc
+}
+
+// MainContainer returns container of the component under test. Container port is published on the same host port
+func (tc *TestCase) MainContainer() TopologyContainer {
+	c := TopologyContainer{
+		Alias:     tc.GetAlias(),
+		Image:     tc.Image,
+		Port:      tc.Port,
+		EnvVars:   tc.EnvVars,
+		HostPort:  tc.Port,
+		DependsOn: tc.DependsOn,
+		Mounts:    append(resolveMounts(tc.Mounts, tc.ComponentType), tc.serverMounts()...),
+		Cmd:       tc.ServerCmd(),
+		Resources: tc.Resources,
+	}
+	if tc.GetNetworkMode() == NetworkMode_Host {
+		// Component listens on the host port itself
+		c.HostPort = 0
+		c.NetworkMode = NetworkMode_Host
+	}
+	if tc.Replication != nil {
+		c.Cmd = postgresPrimaryCmd(c.Cmd)
+	}
+	return c
+}
+
+// ReplicaContainer returns streaming replica of the main container
+func (tc *TestCase) ReplicaContainer() TopologyContainer {
+	return TopologyContainer{
+		Alias:     tc.Replication.GetReplicaAlias(),
+		Image:     tc.Image,
+		Port:      tc.Port,
+		EnvVars:   tc.EnvVars,
+		HostPort:  tc.Replication.GetReplicaHostPort(tc),
+		DependsOn: []string{tc.GetAlias()},
+		Cmd:       postgresReplicaCmd(tc.GetAlias(), tc.Port, tc.ServerCmd()),
+		Mounts:    tc.replicaMounts(),
+		Resources: tc.Resources,
+	}
+}
+
+// replicaMounts returns main container mounts, which could be used by replica.
+// Writable bind mounts are skipped and named volumes become anonymous, because primary and replica data directories must differ.
+// Init scripts aren't mounted, because replica data directory is cloned from primary.
+func (tc *TestCase) replicaMounts() []Mount {
+	var mounts []Mount
+	for _, m := range resolveMounts(tc.Mounts, tc.ComponentType) {
+		switch m.Type {
+		case MountType_Bind:
+			if !m.ReadOnly {
+				continue
+			}
+		case MountType_Volume:
+			m.Source = ""
+		}
+		mounts = append(mounts, m)
+	}
+	for _, f := range tc.ConfigFiles {
+		mounts = append(mounts, Mount{Type: MountType_Bind, Source: f.Source, Target: f.Target, ReadOnly: true})
+	}
+	return mounts
+}
+
+// Containers returns all topology containers including main one in the start order
+func (tc *TestCase) Containers() ([]TopologyContainer, error) {
+	containers := append([]TopologyContainer{tc.MainContainer()}, tc.Topology...)
+	if tc.Replication != nil {
+		containers = append(containers, tc.ReplicaContainer())
+	}
+
+	switch tc.GetNetworkMode() {
+	case NetworkMode_Bridge:
+	case NetworkMode_Host:
+		// Topology containers are resolved by aliases in the dedicated network
+		if len(containers) > 1 {
+			return nil, HOST_NETWORK_WITH_TOPOLOGY
+		}
+	default:
+		return nil, UNKNOWN_NETWORK_MODE
+	}
+
+	return SortTopologyContainers(containers)
+}
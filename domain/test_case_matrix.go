@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TestCaseMatrix describes test case variants. Test case is expanded into cartesian product of all non empty axes
+type TestCaseMatrix struct {
+	Images []string `json:"images,omitempty"`
+	// Env var sets merged into the test case env vars
+	EnvVars []map[string]string `json:"env-vars,omitempty"`
+	// Limits of the main and replica containers. Other topology containers keep their own limits
+	Resources     []ResourceLimits `json:"resources,omitempty"`
+	Accumulations []uint16         `json:"accumulations,omitempty"`
+}
+
+// matrixAxis is a single dimension of the test case expansion
+type matrixAxis struct {
+	name   string
+	values []matrixValue
+}
+
+type matrixValue struct {
+	label string
+	apply func(tc *TestCase)
+}
+
+// ExpandTestCases returns test case for every combination of the matrix and parameters matrix values.
+// Expanded test cases get generated names and keep matrix coordinates. Test cases without matrix are returned as is.
+func ExpandTestCases(tcs []TestCase) []TestCase {
+	var expanded []TestCase
+	for _, tc := range tcs {
+		axes := tc.matrixAxes()
+		if len(axes) == 0 {
+			expanded = append(expanded, tc)
+			continue
+		}
+
+		base := tc
+		base.Matrix = nil
+		base.ParametersMatrix = nil
+
+		combinations := []TestCase{base}
+		for _, axis := range axes {
+			var next []TestCase
+			for _, combination := range combinations {
+				for _, value := range axis.values {
+					etc := combination
+					etc.MatrixCoordinates = make(map[string]string, len(combination.MatrixCoordinates)+1)
+					for k, v := range combination.MatrixCoordinates {
+						etc.MatrixCoordinates[k] = v
+					}
+					etc.MatrixCoordinates[axis.name] = value.label
+					value.apply(&etc)
+					next = append(next, etc)
+				}
+			}
+			combinations = next
+		}
+
+		for _, etc := range combinations {
+			var coordinates []string
+			for _, axis := range axes {
+				coordinates = append(coordinates, axis.name+"="+etc.MatrixCoordinates[axis.name])
+			}
+			etc.Name = tc.matrixBaseName() + "[" + strings.Join(coordinates, ",") + "]"
+			expanded = append(expanded, etc)
+		}
+	}
+	return expanded
+}
+
+func (tc *TestCase) matrixBaseName() string {
+	if tc.Name != "" {
+		return tc.Name
+	} else {
+		return string(tc.ComponentType)
+	}
+}
+
+func (tc *TestCase) matrixAxes() []matrixAxis {
+	var axes []matrixAxis
+
+	if tc.Matrix != nil {
+		if len(tc.Matrix.Images) > 0 {
+			axis := matrixAxis{name: "image"}
+			for _, image := range tc.Matrix.Images {
+				image := image
+				axis.values = append(axis.values, matrixValue{label: image, apply: func(etc *TestCase) { etc.Image = image }})
+			}
+			axes = append(axes, axis)
+		}
+
+		if len(tc.Matrix.EnvVars) > 0 {
+			axis := matrixAxis{name: "env-vars"}
+			for _, envVars := range tc.Matrix.EnvVars {
+				envVars := envVars
+				axis.values = append(axis.values, matrixValue{label: formatSortedMap(envVars), apply: func(etc *TestCase) {
+					etc.EnvVars = mergeMaps(etc.EnvVars, envVars)
+				}})
+			}
+			axes = append(axes, axis)
+		}
+
+		if len(tc.Matrix.Resources) > 0 {
+			axis := matrixAxis{name: "resources"}
+			for _, resources := range tc.Matrix.Resources {
+				resources := resources
+				axis.values = append(axis.values, matrixValue{label: resources.String(), apply: func(etc *TestCase) { etc.Resources = resources }})
+			}
+			axes = append(axes, axis)
+		}
+
+		if len(tc.Matrix.Accumulations) > 0 {
+			axis := matrixAxis{name: "accumulations"}
+			for _, accumulations := range tc.Matrix.Accumulations {
+				accumulations := accumulations
+				axis.values = append(axis.values, matrixValue{label: strconv.Itoa(int(accumulations)), apply: func(etc *TestCase) { etc.Accumulations = accumulations }})
+			}
+			axes = append(axes, axis)
+		}
+	}
+
+	keys := make([]string, 0, len(tc.ParametersMatrix))
+	for k := range tc.ParametersMatrix {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		k := k
+		axis := matrixAxis{name: k}
+		for _, v := range tc.ParametersMatrix[k] {
+			v := v
+			axis.values = append(axis.values, matrixValue{label: v, apply: func(etc *TestCase) {
+				etc.Parameters = mergeMaps(etc.Parameters, map[string]string{k: v})
+			}})
+		}
+		axes = append(axes, axis)
+	}
+
+	return axes
+}
+
+// mergeMaps returns new map with values of both maps. Values of the second map have priority
+func mergeMaps(a, b map[string]string) map[string]string {
+	r := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		r[k] = v
+	}
+	for k, v := range b {
+		r[k] = v
+	}
+	return r
+}
+
+// formatSortedMap formats map as "k1=v1 k2=v2" with sorted keys
+func formatSortedMap(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+m[k])
+	}
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandTestCases(t *testing.T) {
+	tcs := []TestCase{
+		{ComponentType: ComponentType_Postgres, Image: "postgres:14"},
+		{
+			ComponentType: ComponentType_Postgres,
+			EnvVars:       map[string]string{"POSTGRES_PASSWORD": "password"},
+			Matrix: &TestCaseMatrix{
+				Images:    []string{"postgres:13", "postgres:14"},
+				EnvVars:   []map[string]string{{"PGDATA": "/data"}},
+				Resources: []ResourceLimits{{}, {Cpus: 2, MemoryInBytes: 1 << 30}},
+			},
+		},
+	}
+
+	expanded := ExpandTestCases(tcs)
+	if len(expanded) != 5 {
+		t.Fatalf("expanded test cases count = %d, want 5", len(expanded))
+	}
+	if !reflect.DeepEqual(expanded[0], tcs[0]) {
+		t.Errorf("test case without matrix = %+v, want unchanged", expanded[0])
+	}
+
+	expectedNames := []string{
+		"postgres[image=postgres:13,env-vars=PGDATA=/data,resources=cpus:unlimited memory:unlimited]",
+		"postgres[image=postgres:13,env-vars=PGDATA=/data,resources=cpus:2 memory:1073741824]",
+		"postgres[image=postgres:14,env-vars=PGDATA=/data,resources=cpus:unlimited memory:unlimited]",
+		"postgres[image=postgres:14,env-vars=PGDATA=/data,resources=cpus:2 memory:1073741824]",
+	}
+	for i, name := range expectedNames {
+		etc := expanded[i+1]
+		if etc.Name != name {
+			t.Errorf("expanded test case %d name = %s, want %s", i, etc.Name, name)
+		}
+		if etc.Matrix != nil {
+			t.Errorf("expanded test case %d keeps matrix", i)
+		}
+		if etc.Image != etc.MatrixCoordinates["image"] {
+			t.Errorf("expanded test case %d image = %s, coordinates = %v", i, etc.Image, etc.MatrixCoordinates)
+		}
+		if len(etc.EnvVars) != 2 {
+			t.Errorf("expanded test case %d env vars = %v, want merged", i, etc.EnvVars)
+		}
+	}
+	if expanded[2].Resources.Cpus != 2 {
+		t.Errorf("resources = %+v, want 2 cpus", expanded[2].Resources)
+	}
+	if len(tcs[1].EnvVars) != 1 {
+		t.Errorf("source test case env vars were modified: %v", tcs[1].EnvVars)
+	}
+}
+
+func TestExpandTestCases_Parameters(t *testing.T) {
+	tcs := []TestCase{{
+		Name:             "tuning",
+		Parameters:       map[string]string{"fsync": "off"},
+		Matrix:           &TestCaseMatrix{Accumulations: []uint16{4}},
+		ParametersMatrix: map[string][]string{"work_mem": {"4MB", "64MB"}, "shared_buffers": {"128MB", "1GB"}},
+	}}
+
+	expanded := ExpandTestCases(tcs)
+	if len(expanded) != 4 {
+		t.Fatalf("expanded test cases count = %d, want 4", len(expanded))
+	}
+
+	expectedParameters := []map[string]string{
+		{"fsync": "off", "shared_buffers": "128MB", "work_mem": "4MB"},
+		{"fsync": "off", "shared_buffers": "128MB", "work_mem": "64MB"},
+		{"fsync": "off", "shared_buffers": "1GB", "work_mem": "4MB"},
+		{"fsync": "off", "shared_buffers": "1GB", "work_mem": "64MB"},
+	}
+	for i, expected := range expectedParameters {
+		etc := expanded[i]
+		if !reflect.DeepEqual(etc.Parameters, expected) {
+			t.Errorf("expanded test case %d parameters = %v, want %v", i, etc.Parameters, expected)
+		}
+		if etc.Matrix != nil || etc.ParametersMatrix != nil {
+			t.Errorf("expanded test case %d keeps matrix", i)
+		}
+		if etc.Accumulations != 4 {
+			t.Errorf("expanded test case %d accumulations = %d, want 4", i, etc.Accumulations)
+		}
+	}
+	if name := "tuning[accumulations=4,shared_buffers=128MB,work_mem=4MB]"; expanded[0].Name != name {
+		t.Errorf("name = %s, want %s", expanded[0].Name, name)
+	}
+	if len(tcs[0].Parameters) != 1 {
+		t.Errorf("source test case parameters were modified: %v", tcs[0].Parameters)
+	}
+}
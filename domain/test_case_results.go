@@ -1,7 +1,16 @@
 package domain
 
 type TestCaseResults struct {
-	TestCase     TestCase               `json:"test-case"`
+	Name      string     `json:"name"`
+	TestCase  TestCase   `json:"test-case"`
+	ImageInfo *ImageInfo `json:"image-info,omitempty"`
+	// Seed of the generated data
+	DataSeed     int64                  `json:"data-seed"`
+	Storage      *StorageInfo           `json:"storage,omitempty"`
 	Score        float32                `json:"score"`
 	StepsResults []*TestCaseStepResults `json:"steps-results,omitempty"`
+	// Results of rounds on just started container
+	ColdStartStepsResults []*TestCaseStepResults `json:"cold-start-steps-results,omitempty"`
+	// Discarded results of every warmup round
+	WarmupStepsResults [][]*TestCaseStepResults `json:"warmup-steps-results,omitempty"`
 }
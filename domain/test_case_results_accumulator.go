@@ -15,12 +15,48 @@ func (r *TestCaseResultsAccumulator) AddTestCaseStepResultsAccumulator(tcsra *Te
 	r.testCaseStepResultsAccumulators = append(r.testCaseStepResultsAccumulators, tcsra)
 }
 
+// GetTestCaseStepResultsAccumulator returns accumulator for the step with the same name.
+// New accumulator is created on the first call, so results of all accumulation rounds are merged.
+func (r *TestCaseResultsAccumulator) GetTestCaseStepResultsAccumulator(tcs *TestCaseStep) *TestCaseStepResultsAccumulator {
+	for _, tcsra := range r.testCaseStepResultsAccumulators {
+		if tcsra.testCaseStep.Name == tcs.Name {
+			return tcsra
+		}
+	}
+
+	tcsra := NewTestCaseStepResultsAccumulator(tcs)
+	r.AddTestCaseStepResultsAccumulator(tcsra)
+	return tcsra
+}
+
 func (r *TestCaseResultsAccumulator) ToTestCaseResults() *TestCaseResults {
 	tcr := new(TestCaseResults)
+	tcr.Name = r.TestCase.GetName()
+	tcr.TestCase = *r.TestCase
+	tcr.DataSeed = r.TestCase.GetDataSeed()
 
 	for _, v := range r.testCaseStepResultsAccumulators {
-		tcr.StepsResults = append(tcr.StepsResults, v.ToTestCaseStepResults())
+		tcr.StepsResults = append(tcr.StepsResults, v.ToTestCaseStepResults(r.TestCase.OutlierDetection))
 	}
 
 	return tcr
 }
+
+// IsConverged checks that confidence interval of every step duration is narrower than target.
+// Failed steps and steps without duration are skipped, because more rounds don't make them converge.
+func (r *TestCaseResultsAccumulator) IsConverged(cfg *AdaptiveAccumulationConfig) bool {
+	if len(r.testCaseStepResultsAccumulators) == 0 {
+		return false
+	}
+
+	for _, v := range r.testCaseStepResultsAccumulators {
+		if v.HasErrors() || !v.HasMetric(MetricMeta_Duration) {
+			continue
+		}
+		if v.RelativeConfidenceIntervalWidth(MetricMeta_Duration, cfg.GetConfidence(), r.TestCase.OutlierDetection) > cfg.GetTargetRelativeWidth() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,75 @@
+package domain
+
+import "testing"
+
+func TestTestCaseResultsAccumulator_GetTestCaseStepResultsAccumulator(t *testing.T) {
+	tc := &TestCase{Image: "postgres:14"}
+	tcra := NewTestCaseResultsAccumulator(tc)
+
+	// Two rounds with the same steps
+	for round := 0; round < 2; round++ {
+		for _, name := range []string{"first", "second"} {
+			tcra.GetTestCaseStepResultsAccumulator(&TestCaseStep{Name: name}).AddMetric(MetricMeta_Duration, float64(round))
+		}
+	}
+
+	tcr := tcra.ToTestCaseResults()
+	if tcr.TestCase.Image != tc.Image {
+		t.Errorf("image = %q, want %q", tcr.TestCase.Image, tc.Image)
+	}
+	if len(tcr.StepsResults) != 2 {
+		t.Fatalf("steps results count = %d, want 2", len(tcr.StepsResults))
+	}
+	for i, name := range []string{"first", "second"} {
+		if tcr.StepsResults[i].TestCaseStep.Name != name {
+			t.Errorf("step %d name = %q, want %q", i, tcr.StepsResults[i].TestCaseStep.Name, name)
+		}
+		if value := tcr.StepsResults[i].Metrics[0].Value; value != 0.5 {
+			t.Errorf("step %d value = %v, want 0.5", i, value)
+		}
+	}
+}
+
+func TestTestCase_NeedMoreAccumulations(t *testing.T) {
+	convergedTcra := NewTestCaseResultsAccumulator(&TestCase{})
+	for _, v := range []float64{100, 100, 100} {
+		convergedTcra.GetTestCaseStepResultsAccumulator(&TestCaseStep{Name: "step"}).AddMetric(MetricMeta_Duration, v)
+	}
+	divergedTcra := NewTestCaseResultsAccumulator(&TestCase{})
+	for _, v := range []float64{10, 100, 1000} {
+		divergedTcra.GetTestCaseStepResultsAccumulator(&TestCaseStep{Name: "step"}).AddMetric(MetricMeta_Duration, v)
+	}
+	// Failed step and step without duration don't prevent convergence of the others
+	partiallyFailedTcra := NewTestCaseResultsAccumulator(&TestCase{})
+	for _, v := range []float64{100, 100, 100} {
+		partiallyFailedTcra.GetTestCaseStepResultsAccumulator(&TestCaseStep{Name: "step"}).AddMetric(MetricMeta_Duration, v)
+	}
+	partiallyFailedTcra.GetTestCaseStepResultsAccumulator(&TestCaseStep{Name: "failed"}).AddError("timeout")
+	partiallyFailedTcra.GetTestCaseStepResultsAccumulator(&TestCaseStep{Name: "failed"}).AddMetric(MetricMeta_Duration, 10)
+	partiallyFailedTcra.GetTestCaseStepResultsAccumulator(&TestCaseStep{Name: "no duration"}).AddMetric(MetricMeta_Tps, 10)
+
+	tests := []struct {
+		name     string
+		tc       TestCase
+		tcra     *TestCaseResultsAccumulator
+		round    int
+		expected bool
+	}{
+		{name: "default count", tc: TestCase{}, tcra: divergedTcra, round: 15, expected: true},
+		{name: "default count reached", tc: TestCase{}, tcra: divergedTcra, round: 16, expected: false},
+		{name: "fixed count reached", tc: TestCase{Accumulations: 3}, tcra: divergedTcra, round: 3, expected: false},
+		{name: "adaptive min", tc: TestCase{Adaptive: &AdaptiveAccumulationConfig{}}, tcra: convergedTcra, round: 2, expected: true},
+		{name: "adaptive converged", tc: TestCase{Adaptive: &AdaptiveAccumulationConfig{}}, tcra: convergedTcra, round: 3, expected: false},
+		{name: "adaptive not converged", tc: TestCase{Adaptive: &AdaptiveAccumulationConfig{}}, tcra: divergedTcra, round: 3, expected: true},
+		{name: "adaptive skips failed and duration-less steps", tc: TestCase{Adaptive: &AdaptiveAccumulationConfig{}}, tcra: partiallyFailedTcra, round: 3, expected: false},
+		{name: "adaptive max", tc: TestCase{Adaptive: &AdaptiveAccumulationConfig{MaxAccumulations: 3}}, tcra: divergedTcra, round: 3, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := tt.tc.NeedMoreAccumulations(tt.tcra, tt.round); actual != tt.expected {
+				t.Errorf("NeedMoreAccumulations() = %v, want %v", actual, tt.expected)
+			}
+		})
+	}
+}
@@ -3,8 +3,14 @@ package domain
 import "bytes"
 
 type TestCaseStep struct {
-	Name     string
-	StepFunc func() error
+	Name     string       `json:"name"`
+	StepFunc func() error `json:"-"`
+	// Count of operations done by the step, e.g. inserted rows. Throughput and per operation latency are added if set.
+	// Could be set by StepFunc, if the count is known only after execution.
+	OperationsCount int64 `json:"operations-count,omitempty"`
+	// Size of the data sent by the step. Payload throughput is added if set.
+	// Should be known before the step, so its calculation isn't measured
+	PayloadSizeInBytes int64 `json:"payload-size-in-bytes,omitempty"`
 }
 
 func (s *TestCaseStep) String() string {
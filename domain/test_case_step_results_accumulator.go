@@ -1,30 +1,60 @@
 package domain
 
 import (
+	"math"
+
 	"github.com/sirupsen/logrus"
 	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
 )
 
 type TestCaseStepResultsAccumulator struct {
 	testCaseStep *TestCaseStep
 	// TODO Refactor onto interface
-	metricsMap map[MetricMeta][]float64
+	metricsMap map[metricKey][]float64
 	errors     []string
 }
 
+// metricKey identifies metric of the particular container, network interface or block device. Container is empty for step level metrics
+type metricKey struct {
+	meta      MetricMeta
+	container string
+	iface     string
+	device    string
+}
+
 func NewTestCaseStepResultsAccumulator(tcs *TestCaseStep) *TestCaseStepResultsAccumulator {
 	r := new(TestCaseStepResultsAccumulator)
 	r.testCaseStep = tcs
-	r.metricsMap = make(map[MetricMeta][]float64)
+	r.metricsMap = make(map[metricKey][]float64)
 	return r
 }
 
 func (r *TestCaseStepResultsAccumulator) AddMetric(meta *MetricMeta, value float64) {
-	logrus.WithFields(logrus.Fields{"meta": *meta, "value": value}).Debug("add test case step result metric")
-	if values, ok := r.metricsMap[*meta]; ok {
-		r.metricsMap[*meta] = append(values, value)
+	r.AddContainerMetric("", meta, value)
+}
+
+// AddContainerMetric adds metric labeled with the container alias
+func (r *TestCaseStepResultsAccumulator) AddContainerMetric(container string, meta *MetricMeta, value float64) {
+	r.AddInterfaceMetric(container, "", meta, value)
+}
+
+// AddInterfaceMetric adds metric labeled with the container alias and the network interface name
+func (r *TestCaseStepResultsAccumulator) AddInterfaceMetric(container, iface string, meta *MetricMeta, value float64) {
+	r.addMetric(metricKey{meta: *meta, container: container, iface: iface}, value)
+}
+
+// AddDeviceMetric adds metric labeled with the container alias and the block device number
+func (r *TestCaseStepResultsAccumulator) AddDeviceMetric(container, device string, meta *MetricMeta, value float64) {
+	r.addMetric(metricKey{meta: *meta, container: container, device: device}, value)
+}
+
+func (r *TestCaseStepResultsAccumulator) addMetric(key metricKey, value float64) {
+	logrus.WithFields(logrus.Fields{"meta": key.meta, "container": key.container, "interface": key.iface, "device": key.device, "value": value}).Debug("add test case step result metric")
+	if values, ok := r.metricsMap[key]; ok {
+		r.metricsMap[key] = append(values, value)
 	} else {
-		r.metricsMap[*meta] = []float64{value}
+		r.metricsMap[key] = []float64{value}
 	}
 }
 
@@ -32,11 +62,12 @@ func (r *TestCaseStepResultsAccumulator) AddError(err string) {
 	r.errors = append(r.errors, err)
 }
 
-func (r *TestCaseStepResultsAccumulator) ToTestCaseStepResults() *TestCaseStepResults {
+func (r *TestCaseStepResultsAccumulator) ToTestCaseStepResults(od OutlierDetection) *TestCaseStepResults {
 	var metrics []Metric
 
-	for metricMeta, values := range r.metricsMap {
-		metrics = append(metrics, Metric{Meta: metricMeta, Value: stat.Mean(values, nil)})
+	for key, values := range r.metricsMap {
+		inliers, outliers := od.SplitOutliers(values)
+		metrics = append(metrics, Metric{Meta: key.meta, Container: key.container, Interface: key.iface, Device: key.device, Value: stat.Mean(inliers, nil), Outliers: outliers})
 	}
 
 	return &TestCaseStepResults{
@@ -45,3 +76,30 @@ func (r *TestCaseStepResultsAccumulator) ToTestCaseStepResults() *TestCaseStepRe
 		Errors:       r.errors,
 	}
 }
+
+// HasErrors checks whether the step failed in any round
+func (r *TestCaseStepResultsAccumulator) HasErrors() bool {
+	return len(r.errors) > 0
+}
+
+// HasMetric checks whether the step level metric was added
+func (r *TestCaseStepResultsAccumulator) HasMetric(meta *MetricMeta) bool {
+	return len(r.metricsMap[metricKey{meta: *meta}]) > 0
+}
+
+// RelativeConfidenceIntervalWidth returns width of the Student's t confidence interval for the metric mean value relative to the mean.
+// Outliers are excluded like in the reported value. Returns +Inf if there are not enough values.
+func (r *TestCaseStepResultsAccumulator) RelativeConfidenceIntervalWidth(meta *MetricMeta, confidence float64, od OutlierDetection) float64 {
+	values, _ := od.SplitOutliers(r.metricsMap[metricKey{meta: *meta}])
+	if len(values) < 2 {
+		return math.Inf(1)
+	}
+
+	mean, std := stat.MeanStdDev(values, nil)
+	if mean == 0 {
+		return 0
+	}
+
+	t := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(len(values) - 1)}.Quantile(1 - (1-confidence)/2)
+	return 2 * t * std / math.Sqrt(float64(len(values))) / math.Abs(mean)
+}
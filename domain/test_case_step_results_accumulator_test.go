@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTestCaseStepResultsAccumulator_ToTestCaseStepResults(t *testing.T) {
+	tests := []struct {
+		name             string
+		values           []float64
+		od               OutlierDetection
+		expectedValue    float64
+		expectedOutliers int
+	}{
+		{name: "single value", values: []float64{10}, expectedValue: 10},
+		{name: "mean", values: []float64{1, 2, 3, 4}, expectedValue: 2.5},
+		{name: "outlier averaged without detection", values: []float64{10, 10, 10, 10, 10, 100}, expectedValue: 25},
+		{name: "iqr outlier", values: []float64{10, 10, 10, 10, 10, 100}, od: OutlierDetection_Iqr, expectedValue: 10, expectedOutliers: 1},
+		{name: "mad outlier", values: []float64{9, 10, 11, 9, 11, 10, 100}, od: OutlierDetection_Mad, expectedValue: 10, expectedOutliers: 1},
+		{name: "mad without deviation", values: []float64{10, 10, 10}, od: OutlierDetection_Mad, expectedValue: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tcsra := NewTestCaseStepResultsAccumulator(&TestCaseStep{Name: "step"})
+			for _, v := range tt.values {
+				tcsra.AddMetric(MetricMeta_Duration, v)
+			}
+			tcsra.AddError("error")
+
+			tcsr := tcsra.ToTestCaseStepResults(tt.od)
+			if tcsr.TestCaseStep.Name != "step" {
+				t.Errorf("step name = %q, want %q", tcsr.TestCaseStep.Name, "step")
+			}
+			if len(tcsr.Errors) != 1 {
+				t.Errorf("errors count = %d, want 1", len(tcsr.Errors))
+			}
+			if len(tcsr.Metrics) != 1 {
+				t.Fatalf("metrics count = %d, want 1", len(tcsr.Metrics))
+			}
+			if math.Abs(tcsr.Metrics[0].Value-tt.expectedValue) > 1e-9 {
+				t.Errorf("value = %v, want %v", tcsr.Metrics[0].Value, tt.expectedValue)
+			}
+			if len(tcsr.Metrics[0].Outliers) != tt.expectedOutliers {
+				t.Errorf("outliers = %v, want %d outliers", tcsr.Metrics[0].Outliers, tt.expectedOutliers)
+			}
+		})
+	}
+}
+
+func TestTestCaseStepResultsAccumulator_RelativeConfidenceIntervalWidth(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		od     OutlierDetection
+		check  func(width float64) bool
+	}{
+		{name: "not enough values", values: []float64{10}, check: func(w float64) bool { return math.IsInf(w, 1) }},
+		{name: "same values", values: []float64{10, 10, 10}, check: func(w float64) bool { return w == 0 }},
+		{name: "narrow", values: []float64{100, 101, 99, 100, 100, 101, 99, 100}, check: func(w float64) bool { return w > 0 && w < 0.05 }},
+		{name: "wide", values: []float64{10, 100, 50}, check: func(w float64) bool { return w > 1 }},
+		{name: "outlier excluded", values: []float64{100, 101, 99, 100, 100, 101, 99, 1000}, od: OutlierDetection_Iqr, check: func(w float64) bool { return w > 0 && w < 0.05 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tcsra := NewTestCaseStepResultsAccumulator(&TestCaseStep{Name: "step"})
+			for _, v := range tt.values {
+				tcsra.AddMetric(MetricMeta_Duration, v)
+			}
+
+			if width := tcsra.RelativeConfidenceIntervalWidth(MetricMeta_Duration, 0.95, tt.od); !tt.check(width) {
+				t.Errorf("unexpected width %v", width)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package domain
+
+const (
+	MAIN_CONTAINER_ALIAS = "main"
+)
+
+// TopologyContainer describes additional container of the test case topology
+type TopologyContainer struct {
+	// Name of the container in the topology network
+	Alias   string            `json:"alias"`
+	Image   string            `json:"image"`
+	Port    uint16            `json:"port"`
+	EnvVars map[string]string `json:"env-vars"`
+	// Port on the host to publish container port. Port isn't published if 0
+	HostPort uint16 `json:"host-port,omitempty"`
+	// Aliases of containers, which should be started before this one
+	DependsOn []string `json:"depends-on,omitempty"`
+	// Command overriding the image CMD
+	Cmd    []string `json:"cmd,omitempty"`
+	Mounts []Mount  `json:"mounts,omitempty"`
+	// Limits of this container. Test case resources and its matrix axis aren't applied to it
+	Resources ResourceLimits `json:"resources"`
+	// Network mode of the main container. Topology containers are always in the topology network
+	NetworkMode NetworkMode `json:"network-mode,omitempty"`
+}
+
+func (c *TopologyContainer) ContainerConfig(network string) *ContainerConfig {
+	return &ContainerConfig{
+		Image:     c.Image,
+		EnvVars:   c.EnvVars,
+		Port:      c.Port,
+		HostPort:  c.HostPort,
+		Cmd:       c.Cmd,
+		Mounts:    c.Mounts,
+		Resources: c.Resources,
+		Alias:     c.Alias,
+		Network:   network,
+		// Host network mode is used only by single container topology
+		NetworkMode: c.NetworkMode,
+	}
+}
+
+// RunningContainer is a launched container of the test case topology
+type RunningContainer struct {
+	Alias string `json:"alias"`
+	Id    string `json:"id"`
+}
+
+// RunningTopology is a set of launched containers of the test case
+type RunningTopology struct {
+	NetworkId string
+	// Container of the component under test
+	Main RunningContainer
+	// All containers in start order including main one
+	Containers []RunningContainer
+	// Named volumes created for the containers. Removed with the containers
+	Volumes []string
+}
+
+// SortTopologyContainers returns containers in dependency order.
+// Declaration order is kept for independent containers.
+func SortTopologyContainers(containers []TopologyContainer) ([]TopologyContainer, error) {
+	byAlias := make(map[string]TopologyContainer)
+	for _, c := range containers {
+		if _, ok := byAlias[c.Alias]; ok {
+			return nil, DUPLICATED_CONTAINER_ALIAS
+		}
+		byAlias[c.Alias] = c
+	}
+	for _, c := range containers {
+		for _, dep := range c.DependsOn {
+			if _, ok := byAlias[dep]; !ok {
+				return nil, UNKNOWN_CONTAINER_DEPENDENCY
+			}
+		}
+	}
+
+	var sorted []TopologyContainer
+	started := make(map[string]bool)
+	for len(sorted) < len(containers) {
+		progress := false
+		for _, c := range containers {
+			if started[c.Alias] {
+				continue
+			}
+
+			ready := true
+			for _, dep := range c.DependsOn {
+				if !started[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				sorted = append(sorted, c)
+				started[c.Alias] = true
+				progress = true
+			}
+		}
+
+		if !progress {
+			return nil, CYCLIC_CONTAINER_DEPENDENCY
+		}
+	}
+
+	return sorted, nil
+}
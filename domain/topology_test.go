@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortTopologyContainers(t *testing.T) {
+	tests := []struct {
+		name          string
+		containers    []TopologyContainer
+		expectedOrder []string
+		expectedErr   error
+	}{
+		{
+			name:          "declaration order",
+			containers:    []TopologyContainer{{Alias: "a"}, {Alias: "b"}, {Alias: "c"}},
+			expectedOrder: []string{"a", "b", "c"},
+		},
+		{
+			name: "dependencies",
+			containers: []TopologyContainer{
+				{Alias: "app", DependsOn: []string{"db", "cache"}},
+				{Alias: "replica", DependsOn: []string{"db"}},
+				{Alias: "db"},
+				{Alias: "cache"},
+			},
+			expectedOrder: []string{"db", "cache", "app", "replica"},
+		},
+		{
+			name:        "cycle",
+			containers:  []TopologyContainer{{Alias: "a", DependsOn: []string{"b"}}, {Alias: "b", DependsOn: []string{"a"}}},
+			expectedErr: CYCLIC_CONTAINER_DEPENDENCY,
+		},
+		{
+			name:        "unknown dependency",
+			containers:  []TopologyContainer{{Alias: "a", DependsOn: []string{"b"}}},
+			expectedErr: UNKNOWN_CONTAINER_DEPENDENCY,
+		},
+		{
+			name:        "duplicated alias",
+			containers:  []TopologyContainer{{Alias: "a"}, {Alias: "a"}},
+			expectedErr: DUPLICATED_CONTAINER_ALIAS,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted, err := SortTopologyContainers(tt.containers)
+			if err != tt.expectedErr {
+				t.Fatalf("SortTopologyContainers() error = %v, want %v", err, tt.expectedErr)
+			}
+
+			var order []string
+			for _, c := range sorted {
+				order = append(order, c.Alias)
+			}
+			if !reflect.DeepEqual(order, tt.expectedOrder) {
+				t.Errorf("order = %v, want %v", order, tt.expectedOrder)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package domain
+
+// TpcBConfig describes pgbench-like TPC-B workload
+type TpcBConfig struct {
+	// Scale factor. Each unit adds 1 branch, 10 tellers and 100000 accounts
+	Scale uint16 `json:"scale"`
+	// Number of concurrent clients
+	Clients uint16 `json:"clients"`
+	// Number of transactions executed by each client
+	Transactions uint32 `json:"transactions"`
+}
+
+func (c *TpcBConfig) GetScale() uint16 {
+	if c.Scale == 0 {
+		return 1
+	} else {
+		return c.Scale
+	}
+}
+
+func (c *TpcBConfig) GetClients() uint16 {
+	if c.Clients == 0 {
+		return 1
+	} else {
+		return c.Clients
+	}
+}
+
+func (c *TpcBConfig) GetTransactions() uint32 {
+	if c.Transactions == 0 {
+		return 1000
+	} else {
+		return c.Transactions
+	}
+}
@@ -9,14 +9,31 @@ const (
 	UnitOfMeasurePrefix_None  = ""
 	UnitOfMeasurePrefix_Kilo  = "kilo"
 	UnitOfMeasurePrefix_Mega  = "mega"
+	UnitOfMeasurePrefix_Giga  = "giga"
 	UnitOfMeasurePrefix_Tera  = "tera"
 	UnitOfMeasurePrefix_Peta  = "peta"
+
+	// Binary prefixes of bytes
+	UnitOfMeasurePrefix_Kibi = "kibi"
+	UnitOfMeasurePrefix_Mebi = "mebi"
+	UnitOfMeasurePrefix_Gibi = "gibi"
+	UnitOfMeasurePrefix_Tebi = "tebi"
+	UnitOfMeasurePrefix_Pebi = "pebi"
 )
 
+// UnitOfMeasure is a base unit or compound "unit/unit" one
 type UnitOfMeasure string
 
 const (
-	UnitOfMeasure_Byte   = "byte"
-	UnitOfMeasure_Second = "second"
-	UnitOfMeasure_Piece  = "piece"
+	UnitOfMeasure_Byte        = "byte"
+	UnitOfMeasure_Second      = "second"
+	UnitOfMeasure_Piece       = "piece"
+	UnitOfMeasure_Percent     = "percent"
+	UnitOfMeasure_Row         = "row"
+	UnitOfMeasure_Operation   = "operation"
+	UnitOfMeasure_Transaction = "transaction"
+
+	UnitOfMeasure_TransactionPerSecond = "transaction/second"
+	UnitOfMeasure_BytePerSecond        = "byte/second"
+	UnitOfMeasure_OperationPerSecond   = "operation/second"
 )
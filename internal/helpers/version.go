@@ -0,0 +1,17 @@
+package helpers
+
+import "runtime/debug"
+
+// Version is set on build with -ldflags "-X github.com/iakrevetkho/components-tests/cott/internal/helpers.Version=v1.0.0"
+var Version string
+
+// GetVersion returns build version, module version for go install builds or "devel"
+func GetVersion() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "devel"
+}
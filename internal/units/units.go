@@ -0,0 +1,163 @@
+// Package units converts metric values between unit prefixes and formats them for humans
+package units
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+const (
+	COMPOUND_UNIT_SEPARATOR = "/"
+)
+
+var prefixFactors = map[domain.UnitOfMeasurePrefix]float64{
+	domain.UnitOfMeasurePrefix_Nano:  1e-9,
+	domain.UnitOfMeasurePrefix_Micro: 1e-6,
+	domain.UnitOfMeasurePrefix_Milli: 1e-3,
+	domain.UnitOfMeasurePrefix_None:  1,
+	domain.UnitOfMeasurePrefix_Kilo:  1e3,
+	domain.UnitOfMeasurePrefix_Mega:  1e6,
+	domain.UnitOfMeasurePrefix_Giga:  1e9,
+	domain.UnitOfMeasurePrefix_Tera:  1e12,
+	domain.UnitOfMeasurePrefix_Peta:  1e15,
+	domain.UnitOfMeasurePrefix_Kibi:  1 << 10,
+	domain.UnitOfMeasurePrefix_Mebi:  1 << 20,
+	domain.UnitOfMeasurePrefix_Gibi:  1 << 30,
+	domain.UnitOfMeasurePrefix_Tebi:  1 << 40,
+	domain.UnitOfMeasurePrefix_Pebi:  1 << 50,
+}
+
+var prefixSymbols = map[domain.UnitOfMeasurePrefix]string{
+	domain.UnitOfMeasurePrefix_Nano:  "n",
+	domain.UnitOfMeasurePrefix_Micro: "µ",
+	domain.UnitOfMeasurePrefix_Milli: "m",
+	domain.UnitOfMeasurePrefix_None:  "",
+	domain.UnitOfMeasurePrefix_Kilo:  "k",
+	domain.UnitOfMeasurePrefix_Mega:  "M",
+	domain.UnitOfMeasurePrefix_Giga:  "G",
+	domain.UnitOfMeasurePrefix_Tera:  "T",
+	domain.UnitOfMeasurePrefix_Peta:  "P",
+	domain.UnitOfMeasurePrefix_Kibi:  "Ki",
+	domain.UnitOfMeasurePrefix_Mebi:  "Mi",
+	domain.UnitOfMeasurePrefix_Gibi:  "Gi",
+	domain.UnitOfMeasurePrefix_Tebi:  "Ti",
+	domain.UnitOfMeasurePrefix_Pebi:  "Pi",
+}
+
+var unitSymbols = map[domain.UnitOfMeasure]string{
+	domain.UnitOfMeasure_Byte:        "B",
+	domain.UnitOfMeasure_Second:      "s",
+	domain.UnitOfMeasure_Piece:       "pcs",
+	domain.UnitOfMeasure_Percent:     "%",
+	domain.UnitOfMeasure_Row:         "rows",
+	domain.UnitOfMeasure_Operation:   "ops",
+	domain.UnitOfMeasure_Transaction: "tx",
+}
+
+// Prefixes to scale values to in the ascending order
+var (
+	durationPrefixes = []domain.UnitOfMeasurePrefix{domain.UnitOfMeasurePrefix_Nano, domain.UnitOfMeasurePrefix_Micro, domain.UnitOfMeasurePrefix_Milli, domain.UnitOfMeasurePrefix_None}
+	binaryPrefixes   = []domain.UnitOfMeasurePrefix{domain.UnitOfMeasurePrefix_None, domain.UnitOfMeasurePrefix_Kibi, domain.UnitOfMeasurePrefix_Mebi, domain.UnitOfMeasurePrefix_Gibi, domain.UnitOfMeasurePrefix_Tebi, domain.UnitOfMeasurePrefix_Pebi}
+	decimalPrefixes  = []domain.UnitOfMeasurePrefix{domain.UnitOfMeasurePrefix_None, domain.UnitOfMeasurePrefix_Kilo, domain.UnitOfMeasurePrefix_Mega, domain.UnitOfMeasurePrefix_Giga, domain.UnitOfMeasurePrefix_Tera, domain.UnitOfMeasurePrefix_Peta}
+)
+
+// Factor returns multiplier of the prefix. Unknown prefixes have factor 1
+func Factor(prefix domain.UnitOfMeasurePrefix) float64 {
+	if factor, ok := prefixFactors[prefix]; ok {
+		return factor
+	} else {
+		return 1
+	}
+}
+
+// Convert converts value from one prefix to another, e.g. 2048 kibi bytes into 2 mebi bytes
+func Convert(value float64, from, to domain.UnitOfMeasurePrefix) float64 {
+	return value * Factor(from) / Factor(to)
+}
+
+// Scale returns value with the prefix, which keeps the value in human friendly range.
+// Bytes are scaled by binary prefixes, durations by sub-second ones and percents aren't scaled.
+func Scale(value float64, meta *domain.MetricMeta) (float64, domain.UnitOfMeasurePrefix) {
+	prefixes := scalePrefixes(meta.UnitOfMeasure)
+	if prefixes == nil {
+		return value, meta.UnitOfMeasurePrefix
+	}
+
+	base := Convert(value, meta.UnitOfMeasurePrefix, domain.UnitOfMeasurePrefix_None)
+	if base == 0 || math.IsNaN(base) || math.IsInf(base, 0) {
+		return base, domain.UnitOfMeasurePrefix_None
+	}
+
+	// The largest prefix, which keeps the value not less than 1
+	prefix := prefixes[0]
+	for _, p := range prefixes {
+		if math.Abs(base) >= Factor(p) {
+			prefix = p
+		}
+	}
+	return Convert(base, domain.UnitOfMeasurePrefix_None, prefix), prefix
+}
+
+func scalePrefixes(unit domain.UnitOfMeasure) []domain.UnitOfMeasurePrefix {
+	// Throughput is scaled by the numerator unit
+	base := domain.UnitOfMeasure(strings.SplitN(string(unit), COMPOUND_UNIT_SEPARATOR, 2)[0])
+	switch {
+	case unit == domain.UnitOfMeasure_Percent:
+		return nil
+	case unit == domain.UnitOfMeasure_Second:
+		return durationPrefixes
+	case base == domain.UnitOfMeasure_Byte:
+		return binaryPrefixes
+	default:
+		return decimalPrefixes
+	}
+}
+
+// Symbol returns short unit symbol with the prefix, e.g. MiB/s
+func Symbol(prefix domain.UnitOfMeasurePrefix, unit domain.UnitOfMeasure) string {
+	var symbols []string
+	for _, part := range strings.Split(string(unit), COMPOUND_UNIT_SEPARATOR) {
+		if symbol, ok := unitSymbols[domain.UnitOfMeasure(part)]; ok {
+			symbols = append(symbols, symbol)
+		} else {
+			symbols = append(symbols, part)
+		}
+	}
+
+	prefixSymbol, ok := prefixSymbols[prefix]
+	if !ok {
+		prefixSymbol = string(prefix)
+	}
+	return prefixSymbol + strings.Join(symbols, COMPOUND_UNIT_SEPARATOR)
+}
+
+// Format returns scaled value with the unit symbol, e.g. "1.50 MiB" or "12.3 ms"
+func Format(value float64, meta *domain.MetricMeta) string {
+	scaled, prefix := Scale(value, meta)
+	symbol := Symbol(prefix, meta.UnitOfMeasure)
+	if symbol == "" {
+		return FormatNumber(scaled)
+	}
+	if meta.UnitOfMeasure == domain.UnitOfMeasure_Percent {
+		return FormatNumber(scaled) + symbol
+	}
+	return FormatNumber(scaled) + " " + symbol
+}
+
+// FormatNumber formats value with 3 significant digits for values less than 100
+func FormatNumber(value float64) string {
+	abs := math.Abs(value)
+	switch {
+	case abs == 0 || abs >= 100:
+		return strconv.FormatFloat(value, 'f', 0, 64)
+	case abs >= 10:
+		return strconv.FormatFloat(value, 'f', 1, 64)
+	case abs >= 1:
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	default:
+		return strconv.FormatFloat(value, 'g', 3, 64)
+	}
+}
@@ -0,0 +1,62 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to domain.UnitOfMeasurePrefix
+		expected float64
+	}{
+		{1500, domain.UnitOfMeasurePrefix_Micro, domain.UnitOfMeasurePrefix_Milli, 1.5},
+		{2048, domain.UnitOfMeasurePrefix_Kibi, domain.UnitOfMeasurePrefix_Mebi, 2},
+		{1, domain.UnitOfMeasurePrefix_Giga, domain.UnitOfMeasurePrefix_None, 1e9},
+		{3, domain.UnitOfMeasurePrefix_None, domain.UnitOfMeasurePrefix_None, 3},
+	}
+
+	for _, tt := range tests {
+		if actual := Convert(tt.value, tt.from, tt.to); actual != tt.expected {
+			t.Errorf("Convert(%v, %s, %s) = %v, want %v", tt.value, tt.from, tt.to, actual, tt.expected)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		meta     *domain.MetricMeta
+		expected string
+	}{
+		{"micro duration", 12345, domain.MetricMeta_Duration, "12.3 ms"},
+		{"long duration", 125e6, domain.MetricMeta_Duration, "125 s"},
+		{"nano cpu", 800, domain.MetricMeta_CpuUsage, "800 ns"},
+		{"bytes", 1.5 * (1 << 20), domain.MetricMeta_MemoryUsage, "1.50 MiB"},
+		{"small bytes", 512, domain.MetricMeta_MemoryUsage, "512 B"},
+		{"negative bytes", -2048, domain.MetricMeta_MemoryUsageDiff, "-2.00 KiB"},
+		{"zero", 0, domain.MetricMeta_StorageReadUsage, "0 B"},
+		{"percent", 42.123, domain.MetricMeta_CpuUtilization, "42.1%"},
+		{"pieces", 12000, domain.MetricMeta_NetworkSendPackets, "12.0 kpcs"},
+		{"throughput", 2.5e6, &domain.MetricMeta{UnitOfMeasure: domain.UnitOfMeasure_BytePerSecond}, "2.38 MiB/s"},
+		{"tps", 1500, domain.MetricMeta_Tps, "1.50 ktx/s"},
+		{"unknown unit", 5, &domain.MetricMeta{UnitOfMeasure: "frame"}, "5.00 frame"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := Format(tt.value, tt.meta); actual != tt.expected {
+				t.Errorf("Format(%v) = %q, want %q", tt.value, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSymbol(t *testing.T) {
+	if actual := Symbol(domain.UnitOfMeasurePrefix_Kilo, domain.UnitOfMeasure_OperationPerSecond); actual != "kops/s" {
+		t.Errorf("Symbol() = %s, want kops/s", actual)
+	}
+}
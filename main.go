@@ -3,24 +3,39 @@ package main
 import (
 	"encoding/json"
 	"io/ioutil"
+	"os"
 
 	"github.com/iakrevetkho/components-tests/cott/domain"
 	"github.com/iakrevetkho/components-tests/cott/internal/helpers"
 
 	cl_usecase "github.com/iakrevetkho/components-tests/cott/container_launcher/usecase"
+	dt_repository "github.com/iakrevetkho/components-tests/cott/database_tester/repository"
 	dt_usecase "github.com/iakrevetkho/components-tests/cott/database_tester/usecase"
+	rr_usecase "github.com/iakrevetkho/components-tests/cott/report_renderer/usecase"
+	ri_usecase "github.com/iakrevetkho/components-tests/cott/run_info/usecase"
 	tester_usecase "github.com/iakrevetkho/components-tests/cott/tester/usecase"
 
 	"github.com/jinzhu/configor"
 	"github.com/sirupsen/logrus"
 )
 
+const CONFIG_FILE_PATH = "config.yaml"
+
 var cfg domain.Config
 
 func init() {
-	if err := configor.Load(&cfg, "config.yaml"); err != nil {
+	if err := configor.Load(&cfg, CONFIG_FILE_PATH); err != nil {
 		logrus.WithError(err).Fatal("Can't parse conf")
 	}
+	if err := cfg.ResolveHostPaths(CONFIG_FILE_PATH); err != nil {
+		logrus.WithError(err).Fatal("Couldn't resolve host paths of the conf")
+	}
+	if err := cfg.Report.Validate(); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{"tableFormat": cfg.Report.TableFormat, "tableMetric": cfg.Report.TableMetric}).Fatal("Invalid report conf")
+	}
+	if err := cfg.Filter.Validate(); err != nil {
+		logrus.WithError(err).WithField("filter", cfg.Filter).Fatal("Invalid filter conf")
+	}
 
 	if err := helpers.InitLogger(&cfg); err != nil {
 		logrus.WithError(err).Fatal("Couldn't init logger")
@@ -35,19 +50,27 @@ func init() {
 }
 
 func main() {
-	cluc, err := cl_usecase.NewContainerLauncherUsecase()
+	cluc, err := cl_usecase.NewContainerLauncherUsecase(cfg.Engine, cfg.Registry)
 	if err != nil {
 		logrus.WithError(err).Fatal(domain.COULDNT_INIT_CONTAINER_LAUNCHER)
 	}
 
-	dtuc := dt_usecase.NewDatabaseTesterUsecase(cluc)
+	dtuc := dt_usecase.NewDatabaseTesterUsecase(cluc, dt_repository.NewDatabaseTesterRepository)
 
 	tuc := tester_usecase.NewTesterUsecase(cluc, dtuc)
 
-	report, err := tuc.RunCases(cfg.TestCases)
+	testCases := domain.FilterTestCases(domain.ExpandTestCases(cfg.TestCases), cfg.Filter)
+
+	riuc := ri_usecase.NewRunInfoUsecase(&cfg, testCases, cluc)
+	riuc.Start()
+
+	report, err := tuc.RunCases(testCases)
 	if err != nil {
 		logrus.WithError(err).Error("test case error")
 	}
+	if report != nil {
+		report.RunInfo = riuc.Finish()
+	}
 	logrus.WithField("report", report).Info("test cases done")
 
 	reportBytes, err := json.Marshal(report)
@@ -58,4 +81,29 @@ func main() {
 	if err := ioutil.WriteFile(cfg.Report.FilePath, reportBytes, 0644); err != nil {
 		logrus.WithError(err).Fatal("couldn't write report")
 	}
+
+	if report != nil {
+		renderSummaryTables(rr_usecase.NewReportRendererUsecase(cfg.Report.TableMetric), report)
+	}
+}
+
+// renderSummaryTables prints summary table and appends Markdown one to the file, e.g. CI job summary
+func renderSummaryTables(rruc rr_usecase.ReportRendererUsecase, report *domain.Report) {
+	if err := rruc.Render(os.Stdout, report, cfg.Report.TableFormat); err != nil {
+		logrus.WithError(err).Error("couldn't render summary table")
+	}
+
+	if cfg.Report.MarkdownFilePath == "" {
+		return
+	}
+	f, err := os.OpenFile(cfg.Report.MarkdownFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		logrus.WithError(err).Error("couldn't open markdown summary file")
+		return
+	}
+	defer f.Close()
+
+	if err := rruc.Render(f, report, domain.ReportTableFormat_Markdown); err != nil {
+		logrus.WithError(err).Error("couldn't write markdown summary")
+	}
 }
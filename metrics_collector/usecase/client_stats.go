@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"math"
+	"runtime/metrics"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+const (
+	RUNTIME_METRIC_ALLOCATED_BYTES = "/gc/heap/allocs:bytes"
+	RUNTIME_METRIC_ALLOCATIONS     = "/gc/heap/allocs:objects"
+	RUNTIME_METRIC_GC_PAUSES       = "/gc/pauses:seconds"
+	RUNTIME_METRIC_GC_CYCLES       = "/gc/cycles/total:gc-cycles"
+)
+
+// readClientStats reads resources usage of the cott process. Unlike runtime.ReadMemStats it doesn't stop the world
+func readClientStats() *domain.ClientStats {
+	samples := []metrics.Sample{
+		{Name: RUNTIME_METRIC_ALLOCATED_BYTES},
+		{Name: RUNTIME_METRIC_ALLOCATIONS},
+		{Name: RUNTIME_METRIC_GC_PAUSES},
+		{Name: RUNTIME_METRIC_GC_CYCLES},
+	}
+	metrics.Read(samples)
+
+	stats := new(domain.ClientStats)
+	stats.UserCpuTime, stats.SystemCpuTime = readCpuTimes()
+	for _, sample := range samples {
+		switch sample.Value.Kind() {
+		case metrics.KindUint64:
+			switch sample.Name {
+			case RUNTIME_METRIC_ALLOCATED_BYTES:
+				stats.AllocatedBytes = sample.Value.Uint64()
+			case RUNTIME_METRIC_ALLOCATIONS:
+				stats.Allocations = sample.Value.Uint64()
+			case RUNTIME_METRIC_GC_CYCLES:
+				stats.GcCycles = sample.Value.Uint64()
+			}
+		case metrics.KindFloat64Histogram:
+			stats.GcPauseTime = uint64(histogramSum(sample.Value.Float64Histogram()) * float64(1e9))
+		}
+		// Metrics unsupported by the runtime have KindBad and are left zero
+	}
+
+	return stats
+}
+
+// histogramSum estimates sum of the histogram values by the buckets middles, because runtime histograms don't keep the sum
+func histogramSum(h *metrics.Float64Histogram) float64 {
+	var sum float64
+	for i, count := range h.Counts {
+		if count == 0 {
+			continue
+		}
+
+		lower, upper := h.Buckets[i], h.Buckets[i+1]
+		value := (lower + upper) / 2
+		if math.IsInf(lower, -1) {
+			value = upper
+		} else if math.IsInf(upper, 1) {
+			value = lower
+		}
+		sum += value * float64(count)
+	}
+	return sum
+}
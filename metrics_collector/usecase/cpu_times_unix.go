@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package usecase
+
+import (
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// readCpuTimes returns user and system CPU time of the process in nanoseconds
+func readCpuTimes() (uint64, uint64) {
+	var rusage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &rusage); err != nil {
+		logrus.WithError(err).Debug("couldn't get process resources usage")
+		return 0, 0
+	}
+	return uint64(rusage.Utime.Nano()), uint64(rusage.Stime.Nano())
+}
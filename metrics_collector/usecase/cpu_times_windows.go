@@ -0,0 +1,6 @@
+package usecase
+
+// readCpuTimes returns zero CPU times, because getrusage isn't available on Windows
+func readCpuTimes() (uint64, uint64) {
+	return 0, 0
+}
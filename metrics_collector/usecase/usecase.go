@@ -8,86 +8,205 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-const (
-	DEFAULT_NETWORK = "eth0"
-)
-
 type MetricsCollectorUsecase interface {
 	CollectStepMetrics(step *domain.TestCaseStep) error
+	// AddStepMetric adds metric calculated by the step itself, like TPS or latency
+	AddStepMetric(step *domain.TestCaseStep, meta *domain.MetricMeta, value float64)
 }
 
 type metricsCollectorUsecase struct {
-	containerId string
-	tcra        *domain.TestCaseResultsAccumulator
-	cluc        container_launcher.ContainerLauncherUsecase
+	containers []domain.RunningContainer
+	tcra       *domain.TestCaseResultsAccumulator
+	cluc       container_launcher.ContainerLauncherUsecase
+	// Reads resources usage of the cott process. Replaced in tests
+	clientStatsFunc func() *domain.ClientStats
 }
 
-func NewMetricsCollectorUsecase(tcra *domain.TestCaseResultsAccumulator, cluc container_launcher.ContainerLauncherUsecase, containerId string) MetricsCollectorUsecase {
+// NewMetricsCollectorUsecase creates collector of step metrics for every container. Metrics are labeled with containers aliases
+func NewMetricsCollectorUsecase(tcra *domain.TestCaseResultsAccumulator, cluc container_launcher.ContainerLauncherUsecase, containers []domain.RunningContainer) MetricsCollectorUsecase {
 	mcuc := new(metricsCollectorUsecase)
-	mcuc.containerId = containerId
+	mcuc.containers = containers
 	mcuc.tcra = tcra
 	mcuc.cluc = cluc
+	mcuc.clientStatsFunc = readClientStats
 	return mcuc
 }
 
 // TODO Refactor float64 onto interface{}
 func (mcuc *metricsCollectorUsecase) CollectStepMetrics(step *domain.TestCaseStep) error {
-	tcsra := domain.NewTestCaseStepResultsAccumulator(step)
-
-	stats, err := mcuc.cluc.GetContainerStats(mcuc.containerId)
-	if err != nil {
-		logrus.WithError(err).WithField("step", step).Warn("couldn't get container stats")
-		tcsra.AddError(err.Error())
-		return err
+	// Steps excluded by the filter are executed for the next steps, but not measured
+	if !mcuc.tcra.TestCase.StepFilter.Match(step.Name) {
+		return step.StepFunc()
 	}
 
-	startCpuTotalUsage := stats.CPUStats.CPUUsage.TotalUsage
-	startMemUsage := stats.MemoryStats.Usage
+	tcsra := mcuc.tcra.GetTestCaseStepResultsAccumulator(step)
 
-	var startStorageReadUsage, startStorageWriteUsage uint64
-	for _, blkIoStats := range stats.BlkioStats.IoServiceBytesRecursive {
-		switch blkIoStats.Op {
-		case "Read":
-			startStorageReadUsage = blkIoStats.Value
-		case "Write":
-			startStorageWriteUsage = blkIoStats.Value
+	startStats := make([]*domain.ContainerStats, len(mcuc.containers))
+	startOomKills := make([]*uint64, len(mcuc.containers))
+	for i, container := range mcuc.containers {
+		stats, err := mcuc.cluc.GetContainerStats(container.Id)
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{"step": step, "container": container.Alias}).Warn("couldn't get container stats")
+			tcsra.AddError(err.Error())
+			return err
 		}
+		startStats[i] = stats
+		startOomKills[i] = mcuc.getOomKillsCount(step, container)
 	}
-	startNetworkRxUsage := stats.Networks[DEFAULT_NETWORK].RxBytes
-	startNetworkTxUsage := stats.Networks[DEFAULT_NETWORK].TxBytes
 
+	startClientStats := mcuc.clientStatsFunc()
 	startTime := time.Now()
 	if err := step.StepFunc(); err != nil {
 		logrus.WithError(err).WithField("step", step).Warn("error on step execution")
 		tcsra.AddError(err.Error())
 		return err
 	}
-	tcsra.AddMetric(domain.MetricMeta_Duration, float64(time.Since(startTime).Microseconds()))
+	duration := time.Since(startTime)
+	tcsra.AddMetric(domain.MetricMeta_Duration, float64(duration.Microseconds()))
+	mcuc.addThroughputMetrics(tcsra, step, duration)
+	mcuc.addClientMetrics(tcsra, startClientStats, mcuc.clientStatsFunc())
+
+	// Metrics of the other containers are collected even if one of them failed
+	var stepErr error
+	for i, container := range mcuc.containers {
+		stats, err := mcuc.cluc.GetContainerStats(container.Id)
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{"step": step, "container": container.Alias}).Warn("couldn't get container stats")
+			tcsra.AddError(err.Error())
+			if stepErr == nil {
+				stepErr = err
+			}
+			continue
+		}
+		mcuc.addContainerMetrics(tcsra, container.Alias, startStats[i], stats, duration)
+
+		// Step result is invalid, because the component lost its state
+		if oomKills := mcuc.getOomKillsCount(step, container); startOomKills[i] != nil && oomKills != nil && *oomKills > *startOomKills[i] {
+			logrus.WithFields(logrus.Fields{"step": step, "container": container.Alias}).Warn("OOM kill during the step")
+			tcsra.AddError(domain.OOM_KILL_DURING_STEP.Error())
+			if stepErr == nil {
+				stepErr = domain.OOM_KILL_DURING_STEP
+			}
+		}
+	}
+
+	return stepErr
+}
 
-	stats, err = mcuc.cluc.GetContainerStats(mcuc.containerId)
+// getOomKillsCount returns OOM kills count of the container or nil if it couldn't be read. OOM check is skipped then
+func (mcuc *metricsCollectorUsecase) getOomKillsCount(step *domain.TestCaseStep, container domain.RunningContainer) *uint64 {
+	count, err := mcuc.cluc.GetOomKillsCount(container.Id)
 	if err != nil {
-		logrus.WithError(err).WithField("step", step).Warn("couldn't get container stats")
-		tcsra.AddError(err.Error())
-		return err
+		logrus.WithError(err).WithFields(logrus.Fields{"step": step, "container": container.Alias}).Warn("couldn't get container OOM kills count")
+		return nil
 	}
+	return &count
+}
 
-	var resStorageReadUsage, resStorageWriteUsage float64
-	for _, blkIoStats := range stats.BlkioStats.IoServiceBytesRecursive {
-		switch blkIoStats.Op {
-		case "Read":
-			resStorageReadUsage = float64(blkIoStats.Value) - float64(startStorageReadUsage)
-		case "Write":
-			resStorageWriteUsage = float64(blkIoStats.Value) - float64(startStorageWriteUsage)
-		}
+// addContainerMetrics adds resources usage of the container during the step
+func (mcuc *metricsCollectorUsecase) addContainerMetrics(tcsra *domain.TestCaseStepResultsAccumulator, alias string, startStats, stats *domain.ContainerStats, duration time.Duration) {
+	mcuc.addCpuMetrics(tcsra, alias, startStats, stats, duration)
+	tcsra.AddContainerMetric(alias, domain.MetricMeta_MemoryUsage, float64(stats.MemoryStats.Usage))
+	tcsra.AddContainerMetric(alias, domain.MetricMeta_MemoryUsageDiff, float64(stats.MemoryStats.Usage)-float64(startStats.MemoryStats.Usage))
+	tcsra.AddContainerMetric(alias, domain.MetricMeta_MemoryWorkingSet, float64(stats.MemoryStats.WorkingSet()))
+	tcsra.AddContainerMetric(alias, domain.MetricMeta_MemoryRss, float64(stats.MemoryStats.Rss))
+	tcsra.AddContainerMetric(alias, domain.MetricMeta_MemoryCache, float64(stats.MemoryStats.Cache))
+	tcsra.AddContainerMetric(alias, domain.MetricMeta_MemoryPageFaults, float64(stats.MemoryStats.PageFaults)-float64(startStats.MemoryStats.PageFaults))
+	tcsra.AddContainerMetric(alias, domain.MetricMeta_MemoryMajorPageFaults, float64(stats.MemoryStats.MajorPageFaults)-float64(startStats.MemoryStats.MajorPageFaults))
+	if stats.MemoryStats.MaxUsage > 0 {
+		tcsra.AddContainerMetric(alias, domain.MetricMeta_MemoryPeakUsage, float64(stats.MemoryStats.MaxUsage))
+	}
+	if stats.MemoryStats.Limit > 0 {
+		tcsra.AddContainerMetric(alias, domain.MetricMeta_MemoryUsagePercent, float64(stats.MemoryStats.WorkingSet())/float64(stats.MemoryStats.Limit)*100)
+	}
+
+	// Zero operations counts would be misleading, if the engine doesn't report them
+	withOperations := stats.BlkioStats.HasOperations()
+	mcuc.addStorageMetrics(tcsra, alias, "", startStats.BlkioStats.Total(), stats.BlkioStats.Total(), withOperations)
+	startDevices := startStats.BlkioStats.Devices()
+	for device, deviceStats := range stats.BlkioStats.Devices() {
+		mcuc.addStorageMetrics(tcsra, alias, device, startDevices[device], deviceStats, withOperations)
 	}
 
-	tcsra.AddMetric(domain.MetricMeta_CpuUsage, float64(stats.CPUStats.CPUUsage.TotalUsage)-float64(startCpuTotalUsage))
-	tcsra.AddMetric(domain.MetricMeta_MemoryUsage, float64(stats.MemoryStats.Usage))
-	tcsra.AddMetric(domain.MetricMeta_MemoryUsageDiff, float64(stats.MemoryStats.Usage)-float64(startMemUsage))
-	tcsra.AddMetric(domain.MetricMeta_StorageReadUsage, float64(resStorageReadUsage))
-	tcsra.AddMetric(domain.MetricMeta_StorageWriteUsage, float64(resStorageWriteUsage))
-	tcsra.AddMetric(domain.MetricMeta_NetworkReceiveUsage, float64(stats.Networks[DEFAULT_NETWORK].RxBytes)-float64(startNetworkRxUsage))
-	tcsra.AddMetric(domain.MetricMeta_NetworkSendUsage, float64(stats.Networks[DEFAULT_NETWORK].TxBytes)-float64(startNetworkTxUsage))
+	// Client traffic goes through loopback with host networking
+	includeLoopback := mcuc.tcra.TestCase.GetNetworkMode() == domain.NetworkMode_Host
+	mcuc.addNetworkMetrics(tcsra, alias, "", startStats.NetworksTotal(includeLoopback), stats.NetworksTotal(includeLoopback))
+	for name, network := range stats.Networks {
+		// Interfaces attached during the step have zero start stats
+		mcuc.addNetworkMetrics(tcsra, alias, name, startStats.Networks[name], network)
+	}
+}
+
+// addCpuMetrics adds CPU usage during the step. Utilization is normalized by the stats window, which covers the step, and online CPUs
+func (mcuc *metricsCollectorUsecase) addCpuMetrics(tcsra *domain.TestCaseStepResultsAccumulator, alias string, startStats, stats *domain.ContainerStats, duration time.Duration) {
+	usage := float64(stats.CpuStats.TotalUsage) - float64(startStats.CpuStats.TotalUsage)
+	tcsra.AddContainerMetric(alias, domain.MetricMeta_CpuUsage, usage)
+	tcsra.AddContainerMetric(alias, domain.MetricMeta_CpuUserUsage, float64(stats.CpuStats.UserUsage)-float64(startStats.CpuStats.UserUsage))
+	tcsra.AddContainerMetric(alias, domain.MetricMeta_CpuSystemUsage, float64(stats.CpuStats.SystemUsage)-float64(startStats.CpuStats.SystemUsage))
+	tcsra.AddContainerMetric(alias, domain.MetricMeta_CpuThrottledPeriods, float64(stats.CpuStats.ThrottledPeriods)-float64(startStats.CpuStats.ThrottledPeriods))
+	tcsra.AddContainerMetric(alias, domain.MetricMeta_CpuThrottledTime, float64(stats.CpuStats.ThrottledTime)-float64(startStats.CpuStats.ThrottledTime))
+
+	// Usage is counted between the stats reads, which include stats requests of all containers,
+	// so the same window is used. Step duration is used only if the engine doesn't report read times
+	window := duration
+	if !startStats.Read.IsZero() && stats.Read.After(startStats.Read) {
+		window = stats.Read.Sub(startStats.Read)
+	}
+	if window > 0 && stats.CpuStats.OnlineCpus > 0 {
+		tcsra.AddContainerMetric(alias, domain.MetricMeta_CpuUtilization, usage/float64(window.Nanoseconds())/float64(stats.CpuStats.OnlineCpus)*100)
+	}
+}
+
+// addStorageMetrics adds IO of the block device during the step. Device is empty for the IO of all devices
+func (mcuc *metricsCollectorUsecase) addStorageMetrics(tcsra *domain.TestCaseStepResultsAccumulator, alias, device string, startStats, stats domain.ContainerDeviceIoStats, withOperations bool) {
+	tcsra.AddDeviceMetric(alias, device, domain.MetricMeta_StorageReadUsage, float64(stats.ReadBytes)-float64(startStats.ReadBytes))
+	tcsra.AddDeviceMetric(alias, device, domain.MetricMeta_StorageWriteUsage, float64(stats.WriteBytes)-float64(startStats.WriteBytes))
+	if !withOperations {
+		return
+	}
+	tcsra.AddDeviceMetric(alias, device, domain.MetricMeta_StorageReadOperations, float64(stats.ReadOperations)-float64(startStats.ReadOperations))
+	tcsra.AddDeviceMetric(alias, device, domain.MetricMeta_StorageWriteOperations, float64(stats.WriteOperations)-float64(startStats.WriteOperations))
+}
 
-	return nil
+// addNetworkMetrics adds traffic of the network interface during the step. Interface is empty for the traffic of all interfaces
+func (mcuc *metricsCollectorUsecase) addNetworkMetrics(tcsra *domain.TestCaseStepResultsAccumulator, alias, iface string, startStats, stats domain.ContainerNetworkStats) {
+	tcsra.AddInterfaceMetric(alias, iface, domain.MetricMeta_NetworkReceiveUsage, float64(stats.RxBytes)-float64(startStats.RxBytes))
+	tcsra.AddInterfaceMetric(alias, iface, domain.MetricMeta_NetworkSendUsage, float64(stats.TxBytes)-float64(startStats.TxBytes))
+	tcsra.AddInterfaceMetric(alias, iface, domain.MetricMeta_NetworkReceivePackets, float64(stats.RxPackets)-float64(startStats.RxPackets))
+	tcsra.AddInterfaceMetric(alias, iface, domain.MetricMeta_NetworkSendPackets, float64(stats.TxPackets)-float64(startStats.TxPackets))
+	tcsra.AddInterfaceMetric(alias, iface, domain.MetricMeta_NetworkReceiveDropped, float64(stats.RxDropped)-float64(startStats.RxDropped))
+	tcsra.AddInterfaceMetric(alias, iface, domain.MetricMeta_NetworkSendDropped, float64(stats.TxDropped)-float64(startStats.TxDropped))
+	tcsra.AddInterfaceMetric(alias, iface, domain.MetricMeta_NetworkReceiveErrors, float64(stats.RxErrors)-float64(startStats.RxErrors))
+	tcsra.AddInterfaceMetric(alias, iface, domain.MetricMeta_NetworkSendErrors, float64(stats.TxErrors)-float64(startStats.TxErrors))
+}
+
+// addThroughputMetrics adds metrics derived from the step operations count and payload size
+func (mcuc *metricsCollectorUsecase) addThroughputMetrics(tcsra *domain.TestCaseStepResultsAccumulator, step *domain.TestCaseStep, duration time.Duration) {
+	if duration <= 0 {
+		return
+	}
+	if step.OperationsCount > 0 {
+		tcsra.AddMetric(domain.MetricMeta_OperationsThroughput, float64(step.OperationsCount)/duration.Seconds())
+		tcsra.AddMetric(domain.MetricMeta_OperationLatency, float64(duration.Microseconds())/float64(step.OperationsCount))
+	}
+	if step.PayloadSizeInBytes > 0 {
+		tcsra.AddMetric(domain.MetricMeta_PayloadThroughput, float64(step.PayloadSizeInBytes)/duration.Seconds())
+	}
+}
+
+// addClientMetrics adds resources usage of the cott process during the step
+func (mcuc *metricsCollectorUsecase) addClientMetrics(tcsra *domain.TestCaseStepResultsAccumulator, startStats, stats *domain.ClientStats) {
+	tcsra.AddMetric(domain.MetricMeta_ClientUserCpuTime, float64(stats.UserCpuTime)-float64(startStats.UserCpuTime))
+	tcsra.AddMetric(domain.MetricMeta_ClientSystemCpuTime, float64(stats.SystemCpuTime)-float64(startStats.SystemCpuTime))
+	tcsra.AddMetric(domain.MetricMeta_ClientAllocatedSize, float64(stats.AllocatedBytes)-float64(startStats.AllocatedBytes))
+	tcsra.AddMetric(domain.MetricMeta_ClientAllocations, float64(stats.Allocations)-float64(startStats.Allocations))
+	tcsra.AddMetric(domain.MetricMeta_ClientGcPauseTime, float64(stats.GcPauseTime)-float64(startStats.GcPauseTime))
+	tcsra.AddMetric(domain.MetricMeta_ClientGcCycles, float64(stats.GcCycles)-float64(startStats.GcCycles))
+}
+
+func (mcuc *metricsCollectorUsecase) AddStepMetric(step *domain.TestCaseStep, meta *domain.MetricMeta, value float64) {
+	if !mcuc.tcra.TestCase.StepFilter.Match(step.Name) {
+		return
+	}
+	mcuc.tcra.GetTestCaseStepResultsAccumulator(step).AddMetric(meta, value)
 }
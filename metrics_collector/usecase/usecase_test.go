@@ -0,0 +1,374 @@
+package usecase
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	container_launcher "github.com/iakrevetkho/components-tests/cott/container_launcher/usecase"
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+func TestMetricsCollectorUsecase_CollectStepMetrics(t *testing.T) {
+	stepErr := errors.New("step error")
+	statsErr := errors.New("stats error")
+
+	statsSequence := []*domain.ContainerStats{
+		{
+			Read:        time.Unix(0, 0),
+			CpuStats:    domain.ContainerCpuStats{TotalUsage: 1000, UserUsage: 600, SystemUsage: 400, OnlineCpus: 4, ThrottledPeriods: 1, ThrottledTime: 100},
+			MemoryStats: domain.ContainerMemoryStats{Usage: 4096, Limit: 16384, InactiveFile: 1024, PageFaults: 10, MajorPageFaults: 1},
+			// cgroup v1 entries
+			BlkioStats: domain.ContainerBlkioStats{
+				IoServiceBytesRecursive: []domain.ContainerBlkioStatEntry{
+					{Major: 8, Op: "Read", Value: 100},
+					{Major: 8, Op: "Write", Value: 200},
+					{Major: 8, Op: "Total", Value: 300},
+					{Major: 8, Minor: 16, Op: "Read", Value: 10},
+				},
+				IoServicedRecursive: []domain.ContainerBlkioStatEntry{
+					{Major: 8, Op: "Read", Value: 1},
+					{Major: 8, Op: "Write", Value: 2},
+				},
+			},
+			Networks: map[string]domain.ContainerNetworkStats{
+				"eth0": {RxBytes: 10, TxBytes: 20, RxPackets: 1, TxPackets: 2},
+				"lo":   {RxBytes: 100, TxBytes: 100},
+			},
+		},
+		{
+			// 2000ns of CPU time during 1000ns stats window on 4 CPUs is 50% utilization
+			Read:        time.Unix(0, 1000),
+			CpuStats:    domain.ContainerCpuStats{TotalUsage: 3000, UserUsage: 1800, SystemUsage: 1200, OnlineCpus: 4, ThrottledPeriods: 3, ThrottledTime: 500},
+			MemoryStats: domain.ContainerMemoryStats{Usage: 8192, MaxUsage: 9000, Limit: 16384, Rss: 2048, Cache: 6144, InactiveFile: 4096, PageFaults: 25, MajorPageFaults: 3},
+			// cgroup v2 entries
+			BlkioStats: domain.ContainerBlkioStats{
+				IoServiceBytesRecursive: []domain.ContainerBlkioStatEntry{
+					{Major: 8, Op: "read", Value: 150},
+					{Major: 8, Op: "write", Value: 600},
+					{Major: 8, Minor: 16, Op: "read", Value: 40},
+					{Major: 8, Minor: 16, Op: "write", Value: 8},
+				},
+				IoServicedRecursive: []domain.ContainerBlkioStatEntry{
+					{Major: 8, Op: "read", Value: 4},
+					{Major: 8, Op: "write", Value: 12},
+					{Major: 8, Minor: 16, Op: "write", Value: 1},
+				},
+			},
+			Networks: map[string]domain.ContainerNetworkStats{
+				"eth0": {RxBytes: 15, TxBytes: 40, RxPackets: 3, TxPackets: 5, RxDropped: 1},
+				"eth1": {RxBytes: 7, TxBytes: 9, TxErrors: 2},
+				"lo":   {RxBytes: 300, TxBytes: 300},
+			},
+		},
+	}
+
+	clientStatsSequence := []*domain.ClientStats{
+		{UserCpuTime: 100, SystemCpuTime: 10, AllocatedBytes: 2048, Allocations: 4, GcCycles: 3},
+		{UserCpuTime: 400, SystemCpuTime: 30, AllocatedBytes: 3072, Allocations: 12, GcCycles: 4},
+	}
+
+	tests := []struct {
+		name            string
+		networkMode     domain.NetworkMode
+		oomKills        uint64
+		stepErr         error
+		statsErr        error
+		expectedErr     error
+		expectedMetrics map[string]float64
+		expectedErrors  int
+	}{
+		{
+			name: "success",
+			expectedMetrics: map[string]float64{
+				domain.MetricType_CpuUsage:              2000,
+				domain.MetricType_CpuUserUsage:          1200,
+				domain.MetricType_CpuSystemUsage:        800,
+				domain.MetricType_CpuUtilization:        50,
+				domain.MetricType_CpuThrottledPeriods:   2,
+				domain.MetricType_CpuThrottledTime:      400,
+				domain.MetricType_MemoryUsage:           8192,
+				domain.MetricType_MemoryUsageDiff:       4096,
+				domain.MetricType_MemoryWorkingSet:      4096,
+				domain.MetricType_MemoryRss:             2048,
+				domain.MetricType_MemoryCache:           6144,
+				domain.MetricType_MemoryPeakUsage:       9000,
+				domain.MetricType_MemoryUsagePercent:    25,
+				domain.MetricType_MemoryPageFaults:      15,
+				domain.MetricType_MemoryMajorPageFaults: 2,
+				// Devices are summed
+				domain.MetricType_StorageReadUsage:            80,
+				domain.MetricType_StorageWriteUsage:           408,
+				domain.MetricType_StorageReadOperations:       3,
+				domain.MetricType_StorageWriteOperations:      11,
+				domain.MetricType_StorageReadUsage + "@8:0":   50,
+				domain.MetricType_StorageReadUsage + "@8:16":  30,
+				domain.MetricType_StorageWriteUsage + "@8:16": 8,
+				// Custom network eth1 is attached during the step, loopback is skipped
+				domain.MetricType_NetworkReceiveUsage:           12,
+				domain.MetricType_NetworkSendUsage:              29,
+				domain.MetricType_NetworkReceivePackets:         2,
+				domain.MetricType_NetworkSendPackets:            3,
+				domain.MetricType_NetworkReceiveDropped:         1,
+				domain.MetricType_NetworkSendErrors:             2,
+				domain.MetricType_NetworkReceiveUsage + "@eth0": 5,
+				domain.MetricType_NetworkSendUsage + "@eth1":    9,
+				domain.MetricType_NetworkReceiveUsage + "@lo":   200,
+				domain.MetricType_ClientUserCpuTime:             300,
+				domain.MetricType_ClientSystemCpuTime:           20,
+				domain.MetricType_ClientAllocatedSize:           1024,
+				domain.MetricType_ClientAllocations:             8,
+				domain.MetricType_ClientGcCycles:                1,
+			},
+		},
+		{
+			name:        "host network",
+			networkMode: domain.NetworkMode_Host,
+			expectedMetrics: map[string]float64{
+				domain.MetricType_NetworkReceiveUsage:        212,
+				domain.MetricType_NetworkSendUsage:           229,
+				domain.MetricType_NetworkSendUsage + "@eth0": 20,
+			},
+		},
+		{name: "step error", stepErr: stepErr, expectedErr: stepErr, expectedErrors: 1},
+		{name: "stats error", statsErr: statsErr, expectedErr: statsErr, expectedErrors: 1},
+		{name: "oom kill", oomKills: 1, expectedErr: domain.OOM_KILL_DURING_STEP, expectedErrors: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluc := container_launcher.NewFakeContainerLauncherUsecase()
+			cluc.GetContainerStatsErr = tt.statsErr
+			cluc.GetContainerStatsFunc = func(id string, call int) (*domain.ContainerStats, error) {
+				stats := *statsSequence[call%len(statsSequence)]
+				return &stats, nil
+			}
+			// OOM kill happens between start and end of the step
+			cluc.OomKillsCountFunc = func(id string, call int) uint64 {
+				return uint64(call) * tt.oomKills
+			}
+
+			tc := &domain.TestCase{NetworkMode: tt.networkMode}
+			tcra := domain.NewTestCaseResultsAccumulator(tc)
+			mcuc := NewMetricsCollectorUsecase(tcra, cluc, []domain.RunningContainer{{Id: "fake"}}).(*metricsCollectorUsecase)
+			clientStatsCalls := 0
+			mcuc.clientStatsFunc = func() *domain.ClientStats {
+				clientStatsCalls++
+				return clientStatsSequence[(clientStatsCalls-1)%len(clientStatsSequence)]
+			}
+
+			step := &domain.TestCaseStep{Name: "step", StepFunc: func() error { return tt.stepErr }}
+			if err := mcuc.CollectStepMetrics(step); err != tt.expectedErr {
+				t.Fatalf("CollectStepMetrics() error = %v, want %v", err, tt.expectedErr)
+			}
+
+			tcr := tcra.ToTestCaseResults()
+			if len(tcr.StepsResults) != 1 {
+				t.Fatalf("steps results count = %d, want 1", len(tcr.StepsResults))
+			}
+			tcsr := tcr.StepsResults[0]
+			if len(tcsr.Errors) != tt.expectedErrors {
+				t.Errorf("errors = %v, want %d errors", tcsr.Errors, tt.expectedErrors)
+			}
+
+			metrics := make(map[string]float64)
+			for _, m := range tcsr.Metrics {
+				// Per-interface and per-device metrics are keyed as name@interface and name@device
+				switch {
+				case m.Interface != "":
+					metrics[m.Meta.Name+"@"+m.Interface] = m.Value
+				case m.Device != "":
+					metrics[m.Meta.Name+"@"+m.Device] = m.Value
+				default:
+					metrics[m.Meta.Name] = m.Value
+				}
+			}
+			for name, expected := range tt.expectedMetrics {
+				if actual, ok := metrics[name]; !ok || actual != expected {
+					t.Errorf("metric %s = %v, want %v", name, actual, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestMetricsCollectorUsecase_CollectStepMetrics_OomKillOfOneContainer(t *testing.T) {
+	cluc := container_launcher.NewFakeContainerLauncherUsecase()
+	cluc.GetContainerStatsFunc = func(id string, call int) (*domain.ContainerStats, error) {
+		return &domain.ContainerStats{MemoryStats: domain.ContainerMemoryStats{Usage: uint64(call)}}, nil
+	}
+	// Start counts are read for both containers first, then end counts
+	cluc.OomKillsCountFunc = func(id string, call int) uint64 {
+		if id == "primary" && call >= 2 {
+			return 1
+		}
+		return 0
+	}
+
+	tcra := domain.NewTestCaseResultsAccumulator(&domain.TestCase{})
+	containers := []domain.RunningContainer{{Id: "primary", Alias: "primary"}, {Id: "replica", Alias: "replica"}}
+	mcuc := NewMetricsCollectorUsecase(tcra, cluc, containers).(*metricsCollectorUsecase)
+
+	step := &domain.TestCaseStep{Name: "step", StepFunc: func() error { return nil }}
+	if err := mcuc.CollectStepMetrics(step); err != domain.OOM_KILL_DURING_STEP {
+		t.Fatalf("CollectStepMetrics() error = %v, want %v", err, domain.OOM_KILL_DURING_STEP)
+	}
+
+	tcsr := tcra.ToTestCaseResults().StepsResults[0]
+	if len(tcsr.Errors) != 1 {
+		t.Errorf("errors = %v, want 1 error", tcsr.Errors)
+	}
+	collected := make(map[string]bool)
+	for _, m := range tcsr.Metrics {
+		if m.Meta.Name == domain.MetricType_MemoryUsage {
+			collected[m.Container] = true
+		}
+	}
+	if !collected["primary"] || !collected["replica"] {
+		t.Errorf("memory usage collected for %v, want both containers", collected)
+	}
+	if cluc.GetOomKillsCallsCount != 4 {
+		t.Errorf("OOM kills count calls = %d, want 2 per container", cluc.GetOomKillsCallsCount)
+	}
+}
+
+func TestReadClientStats(t *testing.T) {
+	startStats := readClientStats()
+
+	var buffers [][]byte
+	for i := 0; i < 100; i++ {
+		buffers = append(buffers, make([]byte, 1024))
+	}
+	runtime.GC()
+
+	stats := readClientStats()
+	if stats.AllocatedBytes-startStats.AllocatedBytes < uint64(len(buffers)*1024) {
+		t.Errorf("allocated bytes diff = %d, want at least %d", stats.AllocatedBytes-startStats.AllocatedBytes, len(buffers)*1024)
+	}
+	if stats.Allocations <= startStats.Allocations {
+		t.Errorf("allocations = %d, want more than %d", stats.Allocations, startStats.Allocations)
+	}
+	if stats.GcCycles <= startStats.GcCycles {
+		t.Errorf("gc cycles = %d, want more than %d", stats.GcCycles, startStats.GcCycles)
+	}
+}
+
+func TestMetricsCollectorUsecase_addThroughputMetrics(t *testing.T) {
+	tests := []struct {
+		name            string
+		step            domain.TestCaseStep
+		duration        time.Duration
+		expectedMetrics map[string]float64
+	}{
+		{
+			name:     "operations and payload",
+			step:     domain.TestCaseStep{Name: "step", OperationsCount: 1000, PayloadSizeInBytes: 4096},
+			duration: 2 * time.Second,
+			expectedMetrics: map[string]float64{
+				domain.MetricType_OperationsThroughput: 500,
+				domain.MetricType_OperationLatency:     2000,
+				domain.MetricType_PayloadThroughput:    2048,
+			},
+		},
+		{
+			name:            "operations only",
+			step:            domain.TestCaseStep{Name: "step", OperationsCount: 10},
+			duration:        time.Second,
+			expectedMetrics: map[string]float64{domain.MetricType_OperationsThroughput: 10, domain.MetricType_OperationLatency: 100000},
+		},
+		{name: "not declared", step: domain.TestCaseStep{Name: "step"}, duration: time.Second, expectedMetrics: map[string]float64{}},
+		{name: "zero duration", step: domain.TestCaseStep{Name: "step", OperationsCount: 10}, expectedMetrics: map[string]float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tcra := domain.NewTestCaseResultsAccumulator(&domain.TestCase{})
+			mcuc := NewMetricsCollectorUsecase(tcra, container_launcher.NewFakeContainerLauncherUsecase(), nil).(*metricsCollectorUsecase)
+
+			mcuc.addThroughputMetrics(tcra.GetTestCaseStepResultsAccumulator(&tt.step), &tt.step, tt.duration)
+
+			metrics := make(map[string]float64)
+			for _, m := range tcra.ToTestCaseResults().StepsResults[0].Metrics {
+				metrics[m.Meta.Name] = m.Value
+			}
+			if len(metrics) != len(tt.expectedMetrics) {
+				t.Errorf("metrics = %v, want %v", metrics, tt.expectedMetrics)
+			}
+			for name, expected := range tt.expectedMetrics {
+				if actual, ok := metrics[name]; !ok || actual != expected {
+					t.Errorf("metric %s = %v, want %v", name, actual, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestMetricsCollectorUsecase_addCpuMetrics(t *testing.T) {
+	tests := []struct {
+		name                string
+		startRead, read     time.Time
+		duration            time.Duration
+		expectedUtilization float64
+		expectUtilization   bool
+	}{
+		// 2000ns of CPU time during 4000ns stats window on 4 CPUs is 12.5% utilization, though the step took 100ns
+		{name: "short step in long stats window", startRead: time.Unix(0, 0), read: time.Unix(0, 4000), duration: 100, expectedUtilization: 12.5, expectUtilization: true},
+		{name: "no read times", duration: 1000, expectedUtilization: 50, expectUtilization: true},
+		{name: "zero window"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startStats := &domain.ContainerStats{Read: tt.startRead, CpuStats: domain.ContainerCpuStats{TotalUsage: 1000, OnlineCpus: 4}}
+			stats := &domain.ContainerStats{Read: tt.read, CpuStats: domain.ContainerCpuStats{TotalUsage: 3000, OnlineCpus: 4}}
+
+			tcra := domain.NewTestCaseResultsAccumulator(&domain.TestCase{})
+			step := &domain.TestCaseStep{Name: "step"}
+			mcuc := NewMetricsCollectorUsecase(tcra, container_launcher.NewFakeContainerLauncherUsecase(), nil).(*metricsCollectorUsecase)
+
+			mcuc.addCpuMetrics(tcra.GetTestCaseStepResultsAccumulator(step), "db", startStats, stats, tt.duration)
+
+			utilization, ok := 0.0, false
+			for _, m := range tcra.ToTestCaseResults().StepsResults[0].Metrics {
+				if m.Meta.Name == domain.MetricType_CpuUtilization {
+					utilization, ok = m.Value, true
+				}
+			}
+			if ok != tt.expectUtilization || utilization != tt.expectedUtilization {
+				t.Errorf("utilization = %v (present %v), want %v (present %v)", utilization, ok, tt.expectedUtilization, tt.expectUtilization)
+			}
+		})
+	}
+}
+
+func TestMetricsCollectorUsecase_addContainerMetrics_WithoutIoOperations(t *testing.T) {
+	// Docker on cgroup v2 reports only bytes
+	startStats := &domain.ContainerStats{BlkioStats: domain.ContainerBlkioStats{
+		IoServiceBytesRecursive: []domain.ContainerBlkioStatEntry{{Major: 8, Op: "read", Value: 100}},
+	}}
+	stats := &domain.ContainerStats{BlkioStats: domain.ContainerBlkioStats{
+		IoServiceBytesRecursive: []domain.ContainerBlkioStatEntry{{Major: 8, Op: "read", Value: 300}},
+	}}
+
+	tcra := domain.NewTestCaseResultsAccumulator(&domain.TestCase{})
+	step := &domain.TestCaseStep{Name: "step"}
+	mcuc := NewMetricsCollectorUsecase(tcra, container_launcher.NewFakeContainerLauncherUsecase(), nil).(*metricsCollectorUsecase)
+
+	mcuc.addContainerMetrics(tcra.GetTestCaseStepResultsAccumulator(step), "db", startStats, stats, time.Second)
+
+	metrics := make(map[string]float64)
+	for _, m := range tcra.ToTestCaseResults().StepsResults[0].Metrics {
+		if m.Device == "" {
+			metrics[m.Meta.Name] = m.Value
+		}
+	}
+	if metrics[domain.MetricType_StorageReadUsage] != 200 {
+		t.Errorf("storage read usage = %v, want 200", metrics[domain.MetricType_StorageReadUsage])
+	}
+	for _, name := range []string{domain.MetricType_StorageReadOperations, domain.MetricType_StorageWriteOperations} {
+		if value, ok := metrics[name]; ok {
+			t.Errorf("metric %s = %v, want no metric", name, value)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+	"github.com/iakrevetkho/components-tests/cott/internal/units"
+)
+
+const (
+	// Cell of the step without the metric in the test case results
+	MISSING_VALUE = "-"
+	// Cell of the failed step without the metric
+	ERROR_VALUE = "error"
+	// Suffix of the best value in the text table
+	BEST_VALUE_MARK = "*"
+)
+
+type ReportRendererUsecase interface {
+	// Render writes summary table of the report metric. Steps are rows and test cases are columns
+	Render(w io.Writer, report *domain.Report, format domain.ReportTableFormat) error
+}
+
+type reportRendererUsecase struct {
+	metric string
+}
+
+// summaryTable contains formatted values of the metric
+type summaryTable struct {
+	meta    *domain.MetricMeta
+	columns []string
+	rows    []summaryRow
+}
+
+type summaryRow struct {
+	step  string
+	cells []string
+	// Index of the best value cell. -1 if there is nothing to compare
+	best int
+}
+
+func NewReportRendererUsecase(metric string) ReportRendererUsecase {
+	rruc := new(reportRendererUsecase)
+	rruc.metric = metric
+	return rruc
+}
+
+func (rruc *reportRendererUsecase) Render(w io.Writer, report *domain.Report, format domain.ReportTableFormat) error {
+	switch format {
+	case domain.ReportTableFormat_None:
+		return nil
+	case domain.ReportTableFormat_Text:
+		return rruc.buildTable(report).writeText(w)
+	case domain.ReportTableFormat_Markdown:
+		return rruc.buildTable(report).writeMarkdown(w)
+	default:
+		return domain.UNKNOWN_REPORT_TABLE_FORMAT
+	}
+}
+
+// buildTable collects the metric values of every step. Steps are ordered by the first appearance in the test cases
+func (rruc *reportRendererUsecase) buildTable(report *domain.Report) *summaryTable {
+	st := &summaryTable{meta: &domain.MetricMeta{Name: rruc.metric}}
+
+	rowsIndexes := make(map[string]int)
+	var values [][]*float64
+	for column, tcr := range report.TestCaseResults {
+		st.columns = append(st.columns, tcr.Name)
+		for i := range st.rows {
+			st.rows[i].cells = append(st.rows[i].cells, MISSING_VALUE)
+			values[i] = append(values[i], nil)
+		}
+
+		for _, tcsr := range resultsToShow(tcr) {
+			i, ok := rowsIndexes[tcsr.TestCaseStep.Name]
+			if !ok {
+				i = len(st.rows)
+				rowsIndexes[tcsr.TestCaseStep.Name] = i
+				row := summaryRow{step: tcsr.TestCaseStep.Name, cells: make([]string, len(st.columns))}
+				for j := range row.cells {
+					row.cells[j] = MISSING_VALUE
+				}
+				st.rows = append(st.rows, row)
+				values = append(values, make([]*float64, len(st.columns)))
+			}
+
+			m := rruc.findMetric(tcr, tcsr)
+			if m == nil {
+				if len(tcsr.Errors) > 0 {
+					st.rows[i].cells[column] = ERROR_VALUE
+				}
+				continue
+			}
+			meta := m.Meta
+			st.meta = &meta
+			value := m.Value
+			values[i][column] = &value
+			st.rows[i].cells[column] = units.Format(value, &meta)
+		}
+	}
+
+	for i := range st.rows {
+		st.rows[i].best = bestValueIndex(values[i], st.meta.HigherIsBetter)
+	}
+	return st
+}
+
+// resultsToShow returns measured steps results. Cold start results are used if there are no other, e.g. for fresh per round lifecycle
+func resultsToShow(tcr *domain.TestCaseResults) []*domain.TestCaseStepResults {
+	if len(tcr.StepsResults) == 0 {
+		return tcr.ColdStartStepsResults
+	} else {
+		return tcr.StepsResults
+	}
+}
+
+// findMetric returns the step metric of the main container. Step level metric has priority
+func (rruc *reportRendererUsecase) findMetric(tcr *domain.TestCaseResults, tcsr *domain.TestCaseStepResults) *domain.Metric {
+	var containerMetric *domain.Metric
+	for i := range tcsr.Metrics {
+		m := &tcsr.Metrics[i]
+		if m.Meta.Name != rruc.metric || m.Interface != "" || m.Device != "" {
+			continue
+		}
+		if m.Container == "" {
+			return m
+		}
+		if m.Container == tcr.TestCase.GetAlias() && containerMetric == nil {
+			containerMetric = m
+		}
+	}
+	return containerMetric
+}
+
+// bestValueIndex returns index of the best value. -1 if there are less than 2 values
+func bestValueIndex(values []*float64, higherIsBetter bool) int {
+	best := -1
+	count := 0
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		count++
+		if best == -1 || (higherIsBetter && *v > *values[best]) || (!higherIsBetter && *v < *values[best]) {
+			best = i
+		}
+	}
+	if count < 2 {
+		return -1
+	}
+	return best
+}
+
+// caption describes the metric and its best value direction
+func (st *summaryTable) caption() string {
+	if st.meta.HigherIsBetter {
+		return st.meta.Name + ", higher is better"
+	} else {
+		return st.meta.Name + ", lower is better"
+	}
+}
+
+// writeText writes table aligned by spaces. Values are aligned right and the best one is marked
+func (st *summaryTable) writeText(w io.Writer) error {
+	header := []string{"step"}
+	for _, column := range st.columns {
+		header = append(header, column+" ")
+	}
+	lines := [][]string{header}
+	for _, row := range st.rows {
+		line := []string{row.step}
+		for i, cell := range row.cells {
+			if i == row.best {
+				line = append(line, cell+BEST_VALUE_MARK)
+			} else {
+				// Keep values aligned with the marked one
+				line = append(line, cell+" ")
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	widths := make([]int, len(header))
+	for _, line := range lines {
+		for i, cell := range line {
+			if width := utf8.RuneCountInString(cell); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(st.caption() + " (" + BEST_VALUE_MARK + " best)\n")
+	for _, line := range lines {
+		var lb strings.Builder
+		for i, cell := range line {
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if i == 0 {
+				lb.WriteString(cell + padding)
+			} else {
+				lb.WriteString("  " + padding + cell)
+			}
+		}
+		sb.WriteString(strings.TrimRight(lb.String(), " ") + "\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeMarkdown writes GitHub-flavored Markdown table. The best value is bold
+func (st *summaryTable) writeMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**%s**\n\n", escapeMarkdown(st.caption()))
+
+	sb.WriteString("| Step |")
+	for _, column := range st.columns {
+		sb.WriteString(" " + escapeMarkdown(column) + " |")
+	}
+	sb.WriteString("\n| :--- |" + strings.Repeat(" ---: |", len(st.columns)) + "\n")
+
+	for _, row := range st.rows {
+		sb.WriteString("| " + escapeMarkdown(row.step) + " |")
+		for i, cell := range row.cells {
+			if i == row.best {
+				cell = "**" + cell + "**"
+			}
+			sb.WriteString(" " + cell + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// escapeMarkdown escapes characters breaking the table layout or emphasis
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`).Replace(s)
+}
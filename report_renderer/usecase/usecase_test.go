@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+func newTestReport() *domain.Report {
+	duration := *domain.MetricMeta_Duration
+	tps := *domain.MetricMeta_Tps
+
+	report := domain.NewReport()
+	report.AddTestCaseResults(&domain.TestCaseResults{
+		Name: "pg-14",
+		StepsResults: []*domain.TestCaseStepResults{
+			{TestCaseStep: domain.TestCaseStep{Name: "insert"}, Metrics: []domain.Metric{{Meta: duration, Value: 1500}, {Meta: tps, Value: 100}}},
+			{TestCaseStep: domain.TestCaseStep{Name: "select"}, Metrics: []domain.Metric{{Meta: duration, Value: 900}}},
+		},
+	})
+	report.AddTestCaseResults(&domain.TestCaseResults{
+		Name: "pg-15",
+		StepsResults: []*domain.TestCaseStepResults{
+			{TestCaseStep: domain.TestCaseStep{Name: "insert"}, Metrics: []domain.Metric{{Meta: duration, Value: 1200}, {Meta: tps, Value: 120}}},
+			{TestCaseStep: domain.TestCaseStep{Name: "select"}, Errors: []string{"timeout"}},
+			{TestCaseStep: domain.TestCaseStep{Name: "vacuum"}, Metrics: []domain.Metric{{Meta: duration, Value: 5}}},
+		},
+	})
+	return report
+}
+
+func TestReportRendererUsecase_Render(t *testing.T) {
+	tests := []struct {
+		name     string
+		metric   string
+		format   domain.ReportTableFormat
+		expected string
+	}{
+		{
+			name:   "text",
+			metric: domain.MetricType_Duration,
+			format: domain.ReportTableFormat_Text,
+			expected: "duration, lower is better (* best)\n" +
+				"step      pg-14     pg-15\n" +
+				"insert  1.50 ms   1.20 ms*\n" +
+				"select   900 µs     error\n" +
+				"vacuum        -   5.00 µs\n",
+		},
+		{
+			name:   "markdown",
+			metric: domain.MetricType_Tps,
+			format: domain.ReportTableFormat_Markdown,
+			expected: "**tps, higher is better**\n\n" +
+				"| Step | pg-14 | pg-15 |\n" +
+				"| :--- | ---: | ---: |\n" +
+				"| insert | 100 tx/s | **120 tx/s** |\n" +
+				"| select | - | error |\n" +
+				"| vacuum | - | - |\n",
+		},
+		{name: "none", metric: domain.MetricType_Duration, format: domain.ReportTableFormat_None},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := NewReportRendererUsecase(tt.metric).Render(&sb, newTestReport(), tt.format); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if sb.String() != tt.expected {
+				t.Errorf("Render() =\n%s\nwant\n%s", sb.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestReportRendererUsecase_Render_UnknownFormat(t *testing.T) {
+	var sb strings.Builder
+	if err := NewReportRendererUsecase(domain.MetricType_Duration).Render(&sb, newTestReport(), "html"); err != domain.UNKNOWN_REPORT_TABLE_FORMAT {
+		t.Errorf("Render() error = %v, want %v", err, domain.UNKNOWN_REPORT_TABLE_FORMAT)
+	}
+}
+
+func TestReportRendererUsecase_Render_ColdStartResults(t *testing.T) {
+	report := domain.NewReport()
+	// Fresh per round test case has cold start results only
+	report.AddTestCaseResults(&domain.TestCaseResults{
+		Name:                  "pg-14",
+		ColdStartStepsResults: []*domain.TestCaseStepResults{{TestCaseStep: domain.TestCaseStep{Name: "insert"}, Metrics: []domain.Metric{{Meta: *domain.MetricMeta_Duration, Value: 1500}}}},
+	})
+
+	var sb strings.Builder
+	if err := NewReportRendererUsecase(domain.MetricType_Duration).Render(&sb, report, domain.ReportTableFormat_Markdown); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(sb.String(), "| insert | 1.50 ms |") {
+		t.Errorf("Render() =\n%s\nwant insert row with cold start value", sb.String())
+	}
+}
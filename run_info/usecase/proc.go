@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	DISK_SECTOR_SIZE = 512
+)
+
+// cpuTimes is the aggregated "cpu" line of /proc/stat in USER_HZ ticks
+type cpuTimes struct {
+	user, nice, system, idle, ioWait, irq, softIrq, steal uint64
+}
+
+func (c *cpuTimes) total() uint64 {
+	return c.user + c.nice + c.system + c.idle + c.ioWait + c.irq + c.softIrq + c.steal
+}
+
+func (c *cpuTimes) busy() uint64 {
+	return c.user + c.nice + c.system + c.irq + c.softIrq
+}
+
+// diskStats is IO of the physical disks from /proc/diskstats
+type diskStats struct {
+	readBytes, writeBytes uint64
+}
+
+func (riuc *runInfoUsecase) readCpuTimes() (*cpuTimes, error) {
+	content, err := os.ReadFile(filepath.Join(riuc.procPath, "stat"))
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 9 || fields[0] != "cpu" {
+			continue
+		}
+
+		values := make([]uint64, 8)
+		for i := range values {
+			if values[i], err = strconv.ParseUint(fields[i+1], 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		return &cpuTimes{user: values[0], nice: values[1], system: values[2], idle: values[3], ioWait: values[4], irq: values[5], softIrq: values[6], steal: values[7]}, nil
+	}
+
+	return nil, io.EOF
+}
+
+// readDiskStats sums IO of the physical disks. Partitions, loop and device mapper devices are skipped, because they duplicate disks IO
+func (riuc *runInfoUsecase) readDiskStats() (*diskStats, error) {
+	content, err := os.ReadFile(filepath.Join(riuc.procPath, "diskstats"))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := new(diskStats)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		// major minor name reads merged sectors_read ms writes merged sectors_written ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		// Only physical disks have device link
+		if _, err := os.Stat(filepath.Join(riuc.sysPath, "block", fields[2], "device")); err != nil {
+			continue
+		}
+
+		sectorsRead, _ := strconv.ParseUint(fields[5], 10, 64)
+		sectorsWritten, _ := strconv.ParseUint(fields[9], 10, 64)
+		stats.readBytes += sectorsRead * DISK_SECTOR_SIZE
+		stats.writeBytes += sectorsWritten * DISK_SECTOR_SIZE
+	}
+
+	return stats, nil
+}
+
+func (riuc *runInfoUsecase) readLoadAverage() ([]float64, error) {
+	content, err := os.ReadFile(filepath.Join(riuc.procPath, "loadavg"))
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(string(content))
+	if len(fields) < 3 {
+		return nil, io.EOF
+	}
+	loadAverage := make([]float64, 3)
+	for i := range loadAverage {
+		if loadAverage[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return nil, err
+		}
+	}
+	return loadAverage, nil
+}
+
+func (riuc *runInfoUsecase) readKernelVersion() (string, error) {
+	content, err := os.ReadFile(filepath.Join(riuc.procPath, "sys", "kernel", "osrelease"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// readCpuModel returns "model name" of the first CPU from /proc/cpuinfo
+func (riuc *runInfoUsecase) readCpuModel() (string, error) {
+	content, err := os.ReadFile(filepath.Join(riuc.procPath, "cpuinfo"))
+	if err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) == "model name" {
+			return strings.TrimSpace(parts[1]), nil
+		}
+	}
+	return "", io.EOF
+}
+
+// readMemTotal returns host memory size in bytes from "MemTotal: 6147400 kB" line of /proc/meminfo
+func (riuc *runInfoUsecase) readMemTotal() (uint64, error) {
+	content, err := os.ReadFile(filepath.Join(riuc.procPath, "meminfo"))
+	if err != nil {
+		return 0, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 3 && fields[0] == "MemTotal:" {
+			value, err := strconv.ParseUint(fields[1], 10, 64)
+			return value * 1024, err
+		}
+	}
+	return 0, io.EOF
+}
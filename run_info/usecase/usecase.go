@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"os"
+	"runtime"
+	"time"
+
+	container_launcher "github.com/iakrevetkho/components-tests/cott/container_launcher/usecase"
+	"github.com/iakrevetkho/components-tests/cott/domain"
+	"github.com/iakrevetkho/components-tests/cott/internal/helpers"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	PROC_PATH = "/proc"
+	SYS_PATH  = "/sys"
+)
+
+type RunInfoUsecase interface {
+	// Start records the run start time and host load counters
+	Start()
+	// Finish returns environment of the run with the host load since Start
+	Finish() *domain.RunInfo
+}
+
+type runInfoUsecase struct {
+	cfg       *domain.Config
+	testCases []domain.TestCase
+	cluc      container_launcher.ContainerLauncherUsecase
+	procPath  string
+	sysPath   string
+
+	startTime      time.Time
+	startCpuTimes  *cpuTimes
+	startDiskStats *diskStats
+}
+
+// NewRunInfoUsecase creates run info of the cfg run. testCases are the run test cases, which replace the configured ones
+func NewRunInfoUsecase(cfg *domain.Config, testCases []domain.TestCase, cluc container_launcher.ContainerLauncherUsecase) RunInfoUsecase {
+	riuc := new(runInfoUsecase)
+	riuc.cfg = cfg
+	riuc.testCases = testCases
+	riuc.cluc = cluc
+	riuc.procPath = PROC_PATH
+	riuc.sysPath = SYS_PATH
+	return riuc
+}
+
+func (riuc *runInfoUsecase) Start() {
+	riuc.startTime = time.Now()
+
+	var err error
+	if riuc.startCpuTimes, err = riuc.readCpuTimes(); err != nil {
+		logrus.WithError(err).Debug("couldn't read host CPU times")
+	}
+	if riuc.startDiskStats, err = riuc.readDiskStats(); err != nil {
+		logrus.WithError(err).Debug("couldn't read host disk stats")
+	}
+}
+
+func (riuc *runInfoUsecase) Finish() *domain.RunInfo {
+	ri := &domain.RunInfo{
+		CottVersion: helpers.GetVersion(),
+		GoVersion:   runtime.Version(),
+		StartTime:   riuc.startTime,
+		EndTime:     time.Now(),
+		Host:        riuc.readHostInfo(),
+		HostLoad:    riuc.readHostLoad(),
+		Config:      *riuc.cfg,
+	}
+	ri.Config.TestCases = make([]domain.TestCase, len(riuc.testCases))
+	for i := range riuc.testCases {
+		ri.Config.TestCases[i] = riuc.testCases[i].WithDefaults()
+	}
+
+	if engineInfo, err := riuc.cluc.GetEngineInfo(); err != nil {
+		logrus.WithError(err).Warn("couldn't get container engine info")
+	} else {
+		ri.Engine = engineInfo
+	}
+
+	return ri
+}
+
+// readHostInfo reads host description. Fields unavailable on the OS are left empty
+func (riuc *runInfoUsecase) readHostInfo() domain.HostInfo {
+	hi := domain.HostInfo{Os: runtime.GOOS, Arch: runtime.GOARCH, CpuCores: runtime.NumCPU()}
+
+	var err error
+	if hi.Hostname, err = os.Hostname(); err != nil {
+		logrus.WithError(err).Debug("couldn't get hostname")
+	}
+	if hi.KernelVersion, err = riuc.readKernelVersion(); err != nil {
+		logrus.WithError(err).Debug("couldn't read kernel version")
+	}
+	if hi.CpuModel, err = riuc.readCpuModel(); err != nil {
+		logrus.WithError(err).Debug("couldn't read CPU model")
+	}
+	if hi.MemoryInBytes, err = riuc.readMemTotal(); err != nil {
+		logrus.WithError(err).Debug("couldn't read host memory size")
+	}
+
+	return hi
+}
+
+// readHostLoad returns host load since Start. Nil if counters couldn't be read
+func (riuc *runInfoUsecase) readHostLoad() *domain.HostLoad {
+	if riuc.startCpuTimes == nil {
+		return nil
+	}
+	cpu, err := riuc.readCpuTimes()
+	if err != nil {
+		logrus.WithError(err).Debug("couldn't read host CPU times")
+		return nil
+	}
+
+	hl := new(domain.HostLoad)
+	if total := float64(cpu.total() - riuc.startCpuTimes.total()); total > 0 {
+		hl.CpuUtilization = float64(cpu.busy()-riuc.startCpuTimes.busy()) / total * 100
+		hl.CpuIoWait = float64(cpu.ioWait-riuc.startCpuTimes.ioWait) / total * 100
+		hl.CpuSteal = float64(cpu.steal-riuc.startCpuTimes.steal) / total * 100
+	}
+
+	if riuc.startDiskStats != nil {
+		if disk, err := riuc.readDiskStats(); err != nil {
+			logrus.WithError(err).Debug("couldn't read host disk stats")
+		} else if disk.readBytes >= riuc.startDiskStats.readBytes && disk.writeBytes >= riuc.startDiskStats.writeBytes {
+			// Counters are reset if disk is detached during the run
+			hl.DiskReadBytes = disk.readBytes - riuc.startDiskStats.readBytes
+			hl.DiskWriteBytes = disk.writeBytes - riuc.startDiskStats.writeBytes
+		}
+	}
+
+	if hl.LoadAverage, err = riuc.readLoadAverage(); err != nil {
+		logrus.WithError(err).Debug("couldn't read host load average")
+	}
+
+	return hl
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	container_launcher "github.com/iakrevetkho/components-tests/cott/container_launcher/usecase"
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunInfoUsecase(t *testing.T) {
+	procPath := t.TempDir()
+	sysPath := t.TempDir()
+	writeFile(t, filepath.Join(procPath, "sys", "kernel", "osrelease"), "6.1.0-test\n")
+	writeFile(t, filepath.Join(procPath, "cpuinfo"), "processor\t: 0\nmodel name\t: Test CPU @ 3.00GHz\n")
+	writeFile(t, filepath.Join(procPath, "meminfo"), "MemTotal:        1024 kB\nMemFree:          512 kB\n")
+	writeFile(t, filepath.Join(procPath, "loadavg"), "0.50 0.25 0.10 1/100 1000\n")
+	// Only sda is a physical disk, sda1 is its partition
+	writeFile(t, filepath.Join(sysPath, "block", "sda", "device", "model"), "test")
+	writeFile(t, filepath.Join(procPath, "stat"), "cpu  100 0 100 700 50 0 0 50 0 0\ncpu0 100 0 100 700 50 0 0 50 0 0\n")
+	writeFile(t, filepath.Join(procPath, "diskstats"), "   8       0 sda 10 0 100 0 10 0 200 0 0 0 0\n   8       1 sda1 10 0 100 0 10 0 200 0 0 0 0\n")
+
+	cfg := &domain.Config{Report: domain.ReportConfig{FilePath: "report.json"}, TestCases: []domain.TestCase{{Image: "postgres", Matrix: &domain.TestCaseMatrix{}}}}
+	testCases := []domain.TestCase{{Name: "postgres[lifecycle=fresh-per-round]", Image: "postgres", Lifecycle: domain.ContainerLifecycle_FreshPerRound}}
+	riuc := NewRunInfoUsecase(cfg, testCases, container_launcher.NewFakeContainerLauncherUsecase()).(*runInfoUsecase)
+	riuc.procPath = procPath
+	riuc.sysPath = sysPath
+
+	riuc.Start()
+	// 1000 ticks: 300 busy, 500 idle, 100 iowait and 100 steal
+	writeFile(t, filepath.Join(procPath, "stat"), "cpu  250 0 250 1200 150 0 0 150 0 0\n")
+	writeFile(t, filepath.Join(procPath, "diskstats"), "   8       0 sda 20 0 110 0 20 0 400 0 0 0 0\n   8       1 sda1 20 0 110 0 20 0 400 0 0 0 0\n")
+	ri := riuc.Finish()
+
+	if ri.Host.KernelVersion != "6.1.0-test" || ri.Host.CpuModel != "Test CPU @ 3.00GHz" || ri.Host.MemoryInBytes != 1024*1024 {
+		t.Errorf("host = %+v", ri.Host)
+	}
+	if ri.Engine == nil || ri.Engine.StorageDriver != "fake" {
+		t.Errorf("engine = %+v, want fake", ri.Engine)
+	}
+	if ri.Config.Report.FilePath != "report.json" {
+		t.Errorf("config = %+v, want loaded config", ri.Config)
+	}
+	// Run test cases with defaults replace the configured ones
+	if len(ri.Config.TestCases) != 1 {
+		t.Fatalf("config test cases = %+v, want 1 run test case", ri.Config.TestCases)
+	}
+	tc := ri.Config.TestCases[0]
+	if tc.Name != "postgres[lifecycle=fresh-per-round]" || tc.Lifecycle != domain.ContainerLifecycle_FreshPerRound || tc.PullPolicy != domain.PullPolicy_Always ||
+		tc.Alias != domain.MAIN_CONTAINER_ALIAS || tc.Accumulations != 16 || tc.Matrix != nil {
+		t.Errorf("config test case = %+v, want run test case with defaults", tc)
+	}
+	if cfg.TestCases[0].Matrix == nil {
+		t.Errorf("loaded config test cases are modified")
+	}
+	if ri.CottVersion == "" || ri.GoVersion == "" || ri.EndTime.Before(ri.StartTime) {
+		t.Errorf("run info = %+v", ri)
+	}
+
+	if ri.HostLoad == nil {
+		t.Fatalf("host load is missing")
+	}
+	expected := domain.HostLoad{CpuUtilization: 30, CpuIoWait: 10, CpuSteal: 10, DiskReadBytes: 10 * DISK_SECTOR_SIZE, DiskWriteBytes: 200 * DISK_SECTOR_SIZE, LoadAverage: []float64{0.5, 0.25, 0.1}}
+	hl := *ri.HostLoad
+	if hl.CpuUtilization != expected.CpuUtilization || hl.CpuIoWait != expected.CpuIoWait || hl.CpuSteal != expected.CpuSteal ||
+		hl.DiskReadBytes != expected.DiskReadBytes || hl.DiskWriteBytes != expected.DiskWriteBytes || len(hl.LoadAverage) != 3 || hl.LoadAverage[0] != 0.5 {
+		t.Errorf("host load = %+v, want %+v", hl, expected)
+	}
+}
+
+func TestRunInfoUsecase_NoProc(t *testing.T) {
+	riuc := NewRunInfoUsecase(&domain.Config{}, nil, container_launcher.NewFakeContainerLauncherUsecase()).(*runInfoUsecase)
+	riuc.procPath = filepath.Join(t.TempDir(), "missing")
+
+	riuc.Start()
+	ri := riuc.Finish()
+	if ri.HostLoad != nil {
+		t.Errorf("host load = %+v, want nil", ri.HostLoad)
+	}
+	if ri.Host.CpuCores == 0 || ri.Host.Os == "" {
+		t.Errorf("host = %+v, want runtime info", ri.Host)
+	}
+}
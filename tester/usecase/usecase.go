@@ -1,6 +1,9 @@
 package usecase
 
 import (
+	"strconv"
+	"time"
+
 	cl_usecase "github.com/iakrevetkho/components-tests/cott/container_launcher/usecase"
 	dt_usecase "github.com/iakrevetkho/components-tests/cott/database_tester/usecase"
 	"github.com/iakrevetkho/components-tests/cott/domain"
@@ -31,37 +34,239 @@ func (tuc *testerUsecase) RunCases(tcs []domain.TestCase) (*domain.Report, error
 		switch tc.ComponentType {
 
 		case domain.ComponentType_Postgres:
-			containerId, err := tuc.cluc.LaunchContainer(tc.Image, tc.EnvVars, tc.Port)
+			tcr, err := tuc.runDatabaseCase(&tc)
 			if err != nil {
 				return nil, err
 			}
 
-			tcra := domain.NewTestCaseResultsAccumulator(&tc)
+			r.AddTestCaseResults(tcr)
+			logrus.WithField("testResults", tcr).Debug("added test results")
 
-			// Accumulations loop
-			for i := 0; i < int(tc.GetAccumulationsCount()); i++ {
-				if err := tuc.dtuc.RunCase(tcra, *containerId); err != nil {
-					return nil, err
-				}
+		default:
+			return nil, domain.UNKNOWN_COMPONENT_FOR_TESTING
+		}
+	}
+
+	return r, nil
+}
+
+// runDatabaseCase runs accumulation rounds according to the test case container lifecycle.
+// Rounds on just started container are accumulated separately from warm cache rounds.
+func (tuc *testerUsecase) runDatabaseCase(tc *domain.TestCase) (*domain.TestCaseResults, error) {
+	imageInfo, err := tuc.cluc.PullImage(tc.Image, tc.GetPullPolicy())
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithField("imageInfo", imageInfo).Debug("image is ready")
+
+	for _, c := range tc.Topology {
+		topologyImageInfo, err := tuc.cluc.PullImage(c.Image, tc.GetPullPolicy())
+		if err != nil {
+			return nil, err
+		}
+		logrus.WithFields(logrus.Fields{"alias": c.Alias, "imageInfo": topologyImageInfo}).Debug("topology image is ready")
+	}
+
+	storageDriver, err := tuc.cluc.GetStorageDriver()
+	if err != nil {
+		return nil, err
+	}
+	storageInfo := &domain.StorageInfo{Driver: storageDriver, Mounts: tc.MainContainer().Mounts}
+
+	tcra := domain.NewTestCaseResultsAccumulator(tc)
+	coldTcra := domain.NewTestCaseResultsAccumulator(tc)
+	var warmupResults [][]*domain.TestCaseStepResults
+
+	switch tc.GetLifecycle() {
+
+	case domain.ContainerLifecycle_Shared:
+		rt, err := tuc.launchTopology(tc)
+		if err != nil {
+			return nil, err
+		}
+
+		if warmupResults, err = tuc.runWarmup(tc, rt); err != nil {
+			return nil, err
+		}
+
+		// Accumulations loop. Convergence is checked by the warm cache results only
+		for i := 0; tc.NeedMoreAccumulations(tcra, i); i++ {
+			roundTcra := tcra
+			// Container is not cold anymore after warmup.
+			// Cold round is reported separately, so single round gives cold start results only
+			if i == 0 && tc.Warmup == 0 {
+				roundTcra = coldTcra
+			}
+			if err := tuc.dtuc.RunCase(roundTcra, rt); err != nil {
+				return nil, err
 			}
+		}
+
+		if err := tuc.removeTopology(rt); err != nil {
+			return nil, err
+		}
 
-			tcr := tcra.ToTestCaseResults()
+	case domain.ContainerLifecycle_FreshPerRound, domain.ContainerLifecycle_FreshWithWarmup:
+		// Warmup on the separate container to keep every round cold
+		if tc.Warmup > 0 {
+			rt, err := tuc.launchTopology(tc)
+			if err != nil {
+				return nil, err
+			}
 
-			r.AddTestCaseResults(tcr)
-			logrus.WithField("testResults", tcr).Debug("added test results")
+			if warmupResults, err = tuc.runWarmup(tc, rt); err != nil {
+				return nil, err
+			}
 
-			if err := tuc.cluc.StopContainer(*containerId); err != nil {
+			if err := tuc.removeTopology(rt); err != nil {
 				return nil, err
 			}
+		}
 
-			if err := tuc.cluc.RemoveContainer(*containerId); err != nil {
+		// Accumulations loop. Fresh per round results are cold start only
+		measuredTcra := tcra
+		if tc.GetLifecycle() == domain.ContainerLifecycle_FreshPerRound {
+			measuredTcra = coldTcra
+		}
+		for i := 0; tc.NeedMoreAccumulations(measuredTcra, i); i++ {
+			rt, err := tuc.launchTopology(tc)
+			if err != nil {
 				return nil, err
 			}
 
-		default:
-			return nil, domain.UNKNOWN_COMPONENT_FOR_TESTING
+			if err := tuc.dtuc.RunCase(coldTcra, rt); err != nil {
+				return nil, err
+			}
+
+			if tc.GetLifecycle() == domain.ContainerLifecycle_FreshWithWarmup {
+				if err := tuc.dtuc.RunCase(tcra, rt); err != nil {
+					return nil, err
+				}
+			}
+
+			if err := tuc.removeTopology(rt); err != nil {
+				return nil, err
+			}
 		}
+
+	default:
+		return nil, domain.UNKNOWN_CONTAINER_LIFECYCLE
 	}
 
-	return r, nil
+	tcr := tcra.ToTestCaseResults()
+	tcr.ColdStartStepsResults = coldTcra.ToTestCaseResults().StepsResults
+	tcr.WarmupStepsResults = warmupResults
+	tcr.ImageInfo = imageInfo
+	tcr.Storage = storageInfo
+
+	return tcr, nil
+}
+
+// runWarmup runs scenario on the container without feeding test case accumulators.
+// Returns results of every warmup round.
+func (tuc *testerUsecase) runWarmup(tc *domain.TestCase, rt *domain.RunningTopology) ([][]*domain.TestCaseStepResults, error) {
+	var warmupResults [][]*domain.TestCaseStepResults
+
+	for i := 0; i < int(tc.Warmup); i++ {
+		warmupTcra := domain.NewTestCaseResultsAccumulator(tc)
+		if err := tuc.dtuc.RunCase(warmupTcra, rt); err != nil {
+			return nil, err
+		}
+		warmupResults = append(warmupResults, warmupTcra.ToTestCaseResults().StepsResults)
+		logrus.WithFields(logrus.Fields{"round": i + 1, "warmup": tc.Warmup}).Debug("warmup round done")
+	}
+
+	return warmupResults, nil
+}
+
+// launchTopology starts test case containers in dependency order.
+// Single container case is started without dedicated network and its metrics are not labeled.
+func (tuc *testerUsecase) launchTopology(tc *domain.TestCase) (*domain.RunningTopology, error) {
+	rt := new(domain.RunningTopology)
+
+	containers, err := tc.Containers()
+	if err != nil {
+		return nil, err
+	}
+
+	// Named volumes and network are unique for the launch
+	launchId := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	if len(containers) == 1 {
+		cfg := containers[0].ContainerConfig("")
+		rt.Volumes = cfg.ScopeVolumes(launchId)
+		containerId, err := tuc.cluc.LaunchContainer(cfg)
+		if err != nil {
+			if rmErr := tuc.removeTopology(rt); rmErr != nil {
+				logrus.WithError(rmErr).Warn("couldn't remove volumes of not started container")
+			}
+			return nil, err
+		}
+		rt.Main = domain.RunningContainer{Id: *containerId}
+		rt.Containers = []domain.RunningContainer{rt.Main}
+		return rt, nil
+	}
+
+	networkName := "cott-" + launchId
+	networkId, err := tuc.cluc.CreateNetwork(networkName)
+	if err != nil {
+		return nil, err
+	}
+	rt.NetworkId = *networkId
+
+	for _, c := range containers {
+		cfg := c.ContainerConfig(networkName)
+		rt.Volumes = append(rt.Volumes, cfg.ScopeVolumes(launchId)...)
+		containerId, err := tuc.cluc.LaunchContainer(cfg)
+		if err != nil {
+			if rmErr := tuc.removeTopology(rt); rmErr != nil {
+				logrus.WithError(rmErr).Warn("couldn't remove partially started topology")
+			}
+			return nil, err
+		}
+
+		rc := domain.RunningContainer{Alias: c.Alias, Id: *containerId}
+		if c.Alias == tc.GetAlias() {
+			rt.Main = rc
+		}
+		rt.Containers = append(rt.Containers, rc)
+		logrus.WithFields(logrus.Fields{"alias": c.Alias, "id": *containerId}).Debug("topology container started")
+	}
+
+	return rt, nil
+}
+
+// removeTopology stops and removes containers in reverse start order, then their named volumes and the network
+func (tuc *testerUsecase) removeTopology(rt *domain.RunningTopology) error {
+	for i := len(rt.Containers) - 1; i >= 0; i-- {
+		if err := tuc.removeContainer(rt.Containers[i].Id); err != nil {
+			return err
+		}
+	}
+
+	for _, volume := range rt.Volumes {
+		if err := tuc.cluc.RemoveVolume(volume); err != nil {
+			return err
+		}
+	}
+
+	if rt.NetworkId != "" {
+		if err := tuc.cluc.RemoveNetwork(rt.NetworkId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (tuc *testerUsecase) removeContainer(containerId string) error {
+	if err := tuc.cluc.StopContainer(containerId); err != nil {
+		return err
+	}
+
+	if err := tuc.cluc.RemoveContainer(containerId); err != nil {
+		return err
+	}
+
+	return nil
 }
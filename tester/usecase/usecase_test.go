@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	container_launcher "github.com/iakrevetkho/components-tests/cott/container_launcher/usecase"
+	"github.com/iakrevetkho/components-tests/cott/domain"
+)
+
+// stubDatabaseTesterUsecase adds single duration metric on every run.
+// Duration is durations[run] if set, 1 otherwise
+type stubDatabaseTesterUsecase struct {
+	runs      []string
+	durations []float64
+	err       error
+}
+
+func (s *stubDatabaseTesterUsecase) RunCase(tcra *domain.TestCaseResultsAccumulator, rt *domain.RunningTopology) error {
+	duration := 1.0
+	if len(s.runs) < len(s.durations) {
+		duration = s.durations[len(s.runs)]
+	}
+	s.runs = append(s.runs, rt.Main.Id)
+	tcra.GetTestCaseStepResultsAccumulator(&domain.TestCaseStep{Name: "step"}).AddMetric(domain.MetricMeta_Duration, duration)
+	return s.err
+}
+
+func TestTesterUsecase_RunCases(t *testing.T) {
+	launchErr := errors.New("launch error")
+
+	tests := []struct {
+		name                string
+		tc                  domain.TestCase
+		launchErr           error
+		expectedErr         error
+		expectedLaunches    int
+		expectedRuns        int
+		expectedColdResults bool
+		expectedWarmResults bool
+		expectedWarmups     int
+		expectedNetworks    int
+	}{
+		{
+			name:                "shared",
+			tc:                  domain.TestCase{ComponentType: domain.ComponentType_Postgres, Accumulations: 3},
+			expectedLaunches:    1,
+			expectedRuns:        3,
+			expectedColdResults: true,
+			expectedWarmResults: true,
+		},
+		{
+			name:                "shared with warmup",
+			tc:                  domain.TestCase{ComponentType: domain.ComponentType_Postgres, Accumulations: 3, Warmup: 2},
+			expectedLaunches:    1,
+			expectedRuns:        5,
+			expectedWarmResults: true,
+			expectedWarmups:     2,
+		},
+		{
+			name:                "fresh per round",
+			tc:                  domain.TestCase{ComponentType: domain.ComponentType_Postgres, Accumulations: 3, Lifecycle: domain.ContainerLifecycle_FreshPerRound},
+			expectedLaunches:    3,
+			expectedRuns:        3,
+			expectedColdResults: true,
+		},
+		{
+			name:                "fresh with warmup",
+			tc:                  domain.TestCase{ComponentType: domain.ComponentType_Postgres, Accumulations: 3, Lifecycle: domain.ContainerLifecycle_FreshWithWarmup, Warmup: 1},
+			expectedLaunches:    4,
+			expectedRuns:        7,
+			expectedColdResults: true,
+			expectedWarmResults: true,
+			expectedWarmups:     1,
+		},
+		{
+			name: "topology",
+			tc: domain.TestCase{ComponentType: domain.ComponentType_Postgres, Accumulations: 2, Topology: []domain.TopologyContainer{
+				{Alias: "replica", DependsOn: []string{"main"}},
+				{Alias: "exporter"},
+			}},
+			expectedLaunches:    3,
+			expectedRuns:        2,
+			expectedColdResults: true,
+			expectedWarmResults: true,
+			expectedNetworks:    1,
+		},
+		{
+			name:                "replication",
+			tc:                  domain.TestCase{ComponentType: domain.ComponentType_Postgres, Accumulations: 2, Lifecycle: domain.ContainerLifecycle_FreshPerRound, Replication: &domain.ReplicationConfig{Failover: true}},
+			expectedLaunches:    4,
+			expectedRuns:        2,
+			expectedColdResults: true,
+			expectedNetworks:    2,
+		},
+		{
+			name:        "cyclic topology",
+			tc:          domain.TestCase{ComponentType: domain.ComponentType_Postgres, DependsOn: []string{"replica"}, Topology: []domain.TopologyContainer{{Alias: "replica", DependsOn: []string{"main"}}}},
+			expectedErr: domain.CYCLIC_CONTAINER_DEPENDENCY,
+		},
+		{
+			name:             "host network",
+			tc:               domain.TestCase{ComponentType: domain.ComponentType_Postgres, Accumulations: 1, NetworkMode: domain.NetworkMode_Host},
+			expectedLaunches: 1,
+			expectedRuns:     1,
+			// Single round is cold start only
+			expectedColdResults: true,
+		},
+		{
+			name:        "host network with replication",
+			tc:          domain.TestCase{ComponentType: domain.ComponentType_Postgres, NetworkMode: domain.NetworkMode_Host, Replication: &domain.ReplicationConfig{}},
+			expectedErr: domain.HOST_NETWORK_WITH_TOPOLOGY,
+		},
+		{
+			name:        "unknown lifecycle",
+			tc:          domain.TestCase{ComponentType: domain.ComponentType_Postgres, Lifecycle: "unknown"},
+			expectedErr: domain.UNKNOWN_CONTAINER_LIFECYCLE,
+		},
+		{
+			name:        "unknown component",
+			tc:          domain.TestCase{ComponentType: domain.ComponentType_Kafka},
+			expectedErr: domain.UNKNOWN_COMPONENT_FOR_TESTING,
+		},
+		{
+			name:        "launch error",
+			tc:          domain.TestCase{ComponentType: domain.ComponentType_Postgres},
+			launchErr:   launchErr,
+			expectedErr: launchErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluc := container_launcher.NewFakeContainerLauncherUsecase()
+			cluc.LaunchContainerErr = tt.launchErr
+			dtuc := new(stubDatabaseTesterUsecase)
+
+			tuc := NewTesterUsecase(cluc, dtuc)
+			report, err := tuc.RunCases([]domain.TestCase{tt.tc})
+			if err != tt.expectedErr {
+				t.Fatalf("RunCases() error = %v, want %v", err, tt.expectedErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(cluc.LaunchedContainers) != tt.expectedLaunches {
+				t.Errorf("launches = %d, want %d", len(cluc.LaunchedContainers), tt.expectedLaunches)
+			}
+			if len(cluc.RemovedContainers) != tt.expectedLaunches {
+				t.Errorf("removed containers = %d, want %d", len(cluc.RemovedContainers), tt.expectedLaunches)
+			}
+			if len(cluc.CreatedNetworks) != tt.expectedNetworks || len(cluc.RemovedNetworks) != tt.expectedNetworks {
+				t.Errorf("created networks = %v, removed networks = %v, want %d", cluc.CreatedNetworks, cluc.RemovedNetworks, tt.expectedNetworks)
+			}
+			if len(dtuc.runs) != tt.expectedRuns {
+				t.Errorf("runs = %d, want %d", len(dtuc.runs), tt.expectedRuns)
+			}
+
+			if len(report.TestCaseResults) != 1 {
+				t.Fatalf("test case results count = %d, want 1", len(report.TestCaseResults))
+			}
+			tcr := report.TestCaseResults[0]
+			if (len(tcr.ColdStartStepsResults) > 0) != tt.expectedColdResults {
+				t.Errorf("cold start results = %v, want present %v", tcr.ColdStartStepsResults, tt.expectedColdResults)
+			}
+			if (len(tcr.StepsResults) > 0) != tt.expectedWarmResults {
+				t.Errorf("warm results = %v, want present %v", tcr.StepsResults, tt.expectedWarmResults)
+			}
+			if len(tcr.WarmupStepsResults) != tt.expectedWarmups {
+				t.Errorf("warmup rounds = %d, want %d", len(tcr.WarmupStepsResults), tt.expectedWarmups)
+			}
+			if tcr.ImageInfo == nil {
+				t.Errorf("image info is missing")
+			}
+			if tcr.Storage == nil || tcr.Storage.Driver == "" {
+				t.Errorf("storage info = %v, want driver", tcr.Storage)
+			}
+		})
+	}
+}
+
+func TestTesterUsecase_RunCases_NamedVolumes(t *testing.T) {
+	cluc := container_launcher.NewFakeContainerLauncherUsecase()
+	tuc := NewTesterUsecase(cluc, new(stubDatabaseTesterUsecase))
+
+	tc := domain.TestCase{
+		ComponentType: domain.ComponentType_Postgres,
+		Accumulations: 2,
+		Lifecycle:     domain.ContainerLifecycle_FreshPerRound,
+		Mounts:        []domain.Mount{{Type: domain.MountType_Volume, Source: "pgdata"}, {Type: domain.MountType_Tmpfs, Target: "/tmp"}},
+	}
+	if _, err := tuc.RunCases([]domain.TestCase{tc}); err != nil {
+		t.Fatalf("RunCases() error = %v", err)
+	}
+
+	// Every round gets its own volume, which is removed with the container
+	var launchedVolumes []string
+	for _, cfg := range cluc.LaunchedContainers {
+		launchedVolumes = append(launchedVolumes, cfg.Mounts[0].Source)
+	}
+	if len(launchedVolumes) != 2 || launchedVolumes[0] == launchedVolumes[1] || !strings.HasPrefix(launchedVolumes[0], "pgdata-") {
+		t.Errorf("launched volumes = %v, want 2 unique pgdata volumes", launchedVolumes)
+	}
+	if !reflect.DeepEqual(cluc.RemovedVolumes, launchedVolumes) {
+		t.Errorf("removed volumes = %v, want %v", cluc.RemovedVolumes, launchedVolumes)
+	}
+	if tc.Mounts[0].Source != "pgdata" {
+		t.Errorf("test case volume = %s, want pgdata", tc.Mounts[0].Source)
+	}
+}
+
+func TestTesterUsecase_RunCases_SharedColdRoundIsSeparate(t *testing.T) {
+	// Cold round is much slower than the warm ones
+	dtuc := &stubDatabaseTesterUsecase{durations: []float64{1000, 10, 10}}
+	tuc := NewTesterUsecase(container_launcher.NewFakeContainerLauncherUsecase(), dtuc)
+
+	report, err := tuc.RunCases([]domain.TestCase{{ComponentType: domain.ComponentType_Postgres, Accumulations: 3}})
+	if err != nil {
+		t.Fatalf("RunCases() error = %v", err)
+	}
+
+	tcr := report.TestCaseResults[0]
+	if len(tcr.ColdStartStepsResults) != 1 || tcr.ColdStartStepsResults[0].Metrics[0].Value != 1000 {
+		t.Errorf("cold start results = %v, want duration 1000", tcr.ColdStartStepsResults)
+	}
+	if len(tcr.StepsResults) != 1 || tcr.StepsResults[0].Metrics[0].Value != 10 {
+		t.Errorf("warm results = %v, want duration 10 without cold round", tcr.StepsResults)
+	}
+}